@@ -0,0 +1,275 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// ErrLimitExceeded is returned when the adaptive limiter has no capacity for a request
+var ErrLimitExceeded = errors.New("concurrency limit exceeded")
+
+// LimitSample describes a completed request used to adjust the limit
+type LimitSample struct {
+	// RTT is the time from acquiring a slot to receiving the response
+	RTT time.Duration
+	// Inflight is the number of requests in flight when the sample was taken
+	Inflight int
+	// Dropped reports whether the request failed in a way that signals overload
+	Dropped bool
+}
+
+// LimitAlgorithm computes a new concurrency limit from the current limit and a sample.
+// The limiter serializes calls, so implementations may keep state without locking.
+type LimitAlgorithm interface {
+	Update(limit float64, sample LimitSample) float64
+}
+
+// AIMDLimit grows the limit by one while it is used and shrinks it multiplicatively on drops
+type AIMDLimit struct {
+	// BackoffRatio multiplies the limit on a drop
+	BackoffRatio float64
+	// LatencyThreshold treats slower responses as drops, 0 disables the check
+	LatencyThreshold time.Duration
+}
+
+// Update applies additive increase or multiplicative decrease
+func (a AIMDLimit) Update(limit float64, sample LimitSample) float64 {
+	if sample.Dropped || (a.LatencyThreshold > 0 && sample.RTT > a.LatencyThreshold) {
+		ratio := a.BackoffRatio
+		if ratio <= 0 || ratio >= 1 {
+			ratio = 0.9
+		}
+		return limit * ratio
+	}
+
+	// Only grow when the current limit is actually being used
+	if float64(sample.Inflight)*2 >= limit {
+		return limit + 1
+	}
+	return limit
+}
+
+// GradientLimit adjusts the limit by the ratio between the minimum and the current RTT,
+// shrinking as queueing delay builds up and growing while latency stays near the minimum
+type GradientLimit struct {
+	// Tolerance is how much the RTT may exceed the minimum before the limit shrinks
+	Tolerance float64
+	// Smoothing weights new limits against the current one
+	Smoothing float64
+	minRTT    time.Duration
+}
+
+// NewGradientLimit creates a GradientLimit with default tolerance and smoothing
+func NewGradientLimit() *GradientLimit {
+	return &GradientLimit{
+		Tolerance: 1.5,
+		Smoothing: 0.2,
+	}
+}
+
+// MinRTT returns the lowest RTT observed
+func (g *GradientLimit) MinRTT() time.Duration {
+	return g.minRTT
+}
+
+// Update moves the limit toward limit * gradient plus a small queue allowance
+func (g *GradientLimit) Update(limit float64, sample LimitSample) float64 {
+	if sample.Dropped {
+		return limit / 2
+	}
+	if sample.RTT <= 0 {
+		return limit
+	}
+	if g.minRTT == 0 || sample.RTT < g.minRTT {
+		g.minRTT = sample.RTT
+	}
+
+	gradient := g.Tolerance * float64(g.minRTT) / float64(sample.RTT)
+	gradient = math.Max(0.5, math.Min(1, gradient))
+
+	target := limit*gradient + math.Sqrt(limit)
+	return (1-g.Smoothing)*limit + g.Smoothing*target
+}
+
+// AdaptiveLimiterConfig configures an AdaptiveLimiter
+type AdaptiveLimiterConfig struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Algorithm adjusts the limit after every request, defaults to AIMDLimit
+	Algorithm LimitAlgorithm
+	// IsDrop classifies an outcome as overload, defaults to errors, 429, 503 and 504.
+	// Requests failed locally by later pipeline stages are not sampled.
+	IsDrop func(resp *http.Response, err error) bool
+	// OnLimitChange is called after the integer limit changes
+	OnLimitChange func(from, to int)
+	// TimeProvider measures RTTs
+	TimeProvider interfaces.TimeProvider
+}
+
+// DefaultAdaptiveLimiterConfig returns default adaptive limiter configuration
+func DefaultAdaptiveLimiterConfig() AdaptiveLimiterConfig {
+	return AdaptiveLimiterConfig{
+		InitialLimit: 20,
+		MinLimit:     1,
+		MaxLimit:     200,
+		Algorithm:    AIMDLimit{BackoffRatio: 0.9},
+	}
+}
+
+// AdaptiveLimiter caps in-flight requests with a limit learned from observed latency and errors
+type AdaptiveLimiter struct {
+	config   AdaptiveLimiterConfig
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+}
+
+// LimitToken is a slot held by a request
+type LimitToken struct {
+	limiter  *AdaptiveLimiter
+	start    time.Time
+	recorded sync.Once
+	released sync.Once
+}
+
+// NewAdaptiveLimiter creates a new AdaptiveLimiter
+func NewAdaptiveLimiter(config AdaptiveLimiterConfig) *AdaptiveLimiter {
+	defaults := DefaultAdaptiveLimiterConfig()
+	if config.MinLimit <= 0 {
+		config.MinLimit = defaults.MinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defaults.MaxLimit
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = defaults.InitialLimit
+	}
+	if config.Algorithm == nil {
+		config.Algorithm = defaults.Algorithm
+	}
+	if config.IsDrop == nil {
+		config.IsDrop = func(resp *http.Response, err error) bool {
+			if err != nil {
+				return !errors.Is(err, context.Canceled)
+			}
+			return resp.StatusCode == http.StatusTooManyRequests ||
+				resp.StatusCode == http.StatusServiceUnavailable ||
+				resp.StatusCode == http.StatusGatewayTimeout
+		}
+	}
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+
+	l := &AdaptiveLimiter{config: config}
+	l.limit = l.clamp(float64(config.InitialLimit))
+	return l
+}
+
+// WithAdaptiveLimiter caps concurrent requests with the adaptive limiter
+func WithAdaptiveLimiter(limiter *AdaptiveLimiter) Option {
+	return func(c *Client) {
+		c.use(stageGuard, limiter.Middleware())
+	}
+}
+
+// Limit returns the current concurrency limit
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Inflight returns the number of requests holding a slot
+func (l *AdaptiveLimiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inflight
+}
+
+// Acquire takes a slot, failing with ErrLimitExceeded when the limit is reached
+func (l *AdaptiveLimiter) Acquire() (*LimitToken, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inflight >= int(l.limit) {
+		return nil, ErrLimitExceeded
+	}
+	l.inflight++
+
+	return &LimitToken{limiter: l, start: l.config.TimeProvider.Now()}, nil
+}
+
+// Record feeds the RTT since Acquire to the algorithm; only the first call counts
+func (t *LimitToken) Record(dropped bool) {
+	t.recorded.Do(func() {
+		t.limiter.record(t.limiter.config.TimeProvider.Now().Sub(t.start), dropped)
+	})
+}
+
+// Release frees the slot; only the first call counts
+func (t *LimitToken) Release() {
+	t.released.Do(func() {
+		t.limiter.mu.Lock()
+		t.limiter.inflight--
+		t.limiter.mu.Unlock()
+	})
+}
+
+// Middleware returns a middleware that samples RTT when the response arrives
+// and holds the slot until the response body is closed
+func (l *AdaptiveLimiter) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := l.Acquire()
+			if err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err == nil || !isLocalFailure(err) {
+				// Requests failed by later pipeline stages never reached the upstream
+				token.Record(l.config.IsDrop(resp, err))
+			}
+			if err != nil {
+				token.Release()
+				return nil, err
+			}
+
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: token.Release}
+			return resp, nil
+		})
+	}
+}
+
+// record updates the limit with a sample
+func (l *AdaptiveLimiter) record(rtt time.Duration, dropped bool) {
+	l.mu.Lock()
+	from := int(l.limit)
+	l.limit = l.clamp(l.config.Algorithm.Update(l.limit, LimitSample{
+		RTT:      rtt,
+		Inflight: l.inflight,
+		Dropped:  dropped,
+	}))
+	to := int(l.limit)
+	l.mu.Unlock()
+
+	if from != to && l.config.OnLimitChange != nil {
+		l.config.OnLimitChange(from, to)
+	}
+}
+
+// clamp keeps the limit within the configured bounds
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), limit))
+}
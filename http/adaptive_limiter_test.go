@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter_AIMD(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewAdaptiveLimiter(AdaptiveLimiterConfig{
+		InitialLimit: 10,
+		MaxLimit:     12,
+		Algorithm:    AIMDLimit{BackoffRatio: 0.5, LatencyThreshold: 100 * time.Millisecond},
+		TimeProvider: clock,
+	})
+
+	sample := func(rtt time.Duration, inflight int, dropped bool) {
+		var tokens []*LimitToken
+		for i := 0; i < inflight; i++ {
+			token, err := limiter.Acquire()
+			if err != nil {
+				t.Fatalf("AdaptiveLimiter.Acquire() error = %v", err)
+			}
+			tokens = append(tokens, token)
+		}
+		clock.SetTime(clock.Now().Add(rtt))
+		tokens[0].Record(dropped)
+		for _, token := range tokens {
+			token.Release()
+		}
+	}
+
+	sample(10*time.Millisecond, 1, false)
+	if limiter.Limit() != 10 {
+		t.Errorf("AdaptiveLimiter.Limit() = %d with low utilization, want 10", limiter.Limit())
+	}
+
+	sample(10*time.Millisecond, 5, false)
+	sample(10*time.Millisecond, 5, false)
+	sample(10*time.Millisecond, 6, false)
+	if limiter.Limit() != 12 {
+		t.Errorf("AdaptiveLimiter.Limit() = %d after growth, want max 12", limiter.Limit())
+	}
+
+	sample(200*time.Millisecond, 1, false)
+	if limiter.Limit() != 6 {
+		t.Errorf("AdaptiveLimiter.Limit() = %d after slow response, want 6", limiter.Limit())
+	}
+
+	sample(10*time.Millisecond, 1, true)
+	if limiter.Limit() != 3 {
+		t.Errorf("AdaptiveLimiter.Limit() = %d after drop, want 3", limiter.Limit())
+	}
+	if limiter.Inflight() != 0 {
+		t.Errorf("AdaptiveLimiter.Inflight() = %d, want 0", limiter.Inflight())
+	}
+}
+
+func TestAdaptiveLimiter_RejectsAtLimit(t *testing.T) {
+	limiter := NewAdaptiveLimiter(AdaptiveLimiterConfig{InitialLimit: 1, TimeProvider: newFakeClock()})
+
+	token, err := limiter.Acquire()
+	if err != nil {
+		t.Fatalf("AdaptiveLimiter.Acquire() error = %v", err)
+	}
+	if _, err := limiter.Acquire(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("AdaptiveLimiter.Acquire() error = %v, want %v", err, ErrLimitExceeded)
+	}
+
+	token.Release()
+	token.Release()
+	if limiter.Inflight() != 0 {
+		t.Errorf("AdaptiveLimiter.Inflight() = %d after double release, want 0", limiter.Inflight())
+	}
+}
+
+func TestGradientLimit(t *testing.T) {
+	gradient := NewGradientLimit()
+	limit := 20.0
+
+	for i := 0; i < 20; i++ {
+		limit = gradient.Update(limit, LimitSample{RTT: 10 * time.Millisecond})
+	}
+	if limit <= 20 {
+		t.Errorf("GradientLimit grew to %v at minimum RTT, want above 20", limit)
+	}
+	grown := limit
+
+	for i := 0; i < 20; i++ {
+		limit = gradient.Update(limit, LimitSample{RTT: 100 * time.Millisecond})
+	}
+	if limit >= grown {
+		t.Errorf("GradientLimit = %v after latency increase, want below %v", limit, grown)
+	}
+	if gradient.MinRTT() != 10*time.Millisecond {
+		t.Errorf("GradientLimit.MinRTT() = %v, want 10ms", gradient.MinRTT())
+	}
+}
+
+func TestClient_WithAdaptiveLimiter(t *testing.T) {
+	clock := newFakeClock()
+	var changes []int
+	limiter := NewAdaptiveLimiter(AdaptiveLimiterConfig{
+		InitialLimit:  4,
+		Algorithm:     AIMDLimit{BackoffRatio: 0.5},
+		TimeProvider:  clock,
+		OnLimitChange: func(from, to int) { changes = append(changes, to) },
+	})
+
+	// Synthetic upstream that takes 50ms of fake time and is overloaded
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		clock.SetTime(clock.Now().Add(50 * time.Millisecond))
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient("https://search.example.com", WithTransport(transport), WithAdaptiveLimiter(limiter))
+
+	resp, err := client.Get("/search", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	if limiter.Inflight() != 1 {
+		t.Errorf("AdaptiveLimiter.Inflight() = %d while body open, want 1", limiter.Inflight())
+	}
+	resp.Body.Close()
+
+	if limiter.Limit() != 2 || len(changes) != 1 || changes[0] != 2 {
+		t.Errorf("AdaptiveLimiter.Limit() = %d with changes %v, want 2", limiter.Limit(), changes)
+	}
+	if limiter.Inflight() != 0 {
+		t.Errorf("AdaptiveLimiter.Inflight() = %d after close, want 0", limiter.Inflight())
+	}
+}
+
+func TestClient_AdaptiveLimiterIgnoresLocalFailures(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewAdaptiveLimiter(AdaptiveLimiterConfig{
+		InitialLimit: 10,
+		Algorithm:    AIMDLimit{BackoffRatio: 0.5},
+		TimeProvider: clock,
+	})
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("Transport called for a request without deadline budget")
+		return nil, errors.New("unexpected request")
+	})
+	deadlines := DefaultDeadlineConfig()
+	deadlines.TimeProvider = clock
+	client := NewClient("https://search.example.com",
+		WithTransport(transport),
+		WithAdaptiveLimiter(limiter),
+		WithDeadlinePropagation(deadlines),
+	)
+
+	// The budget is spent on the fake clock while the context itself is still live
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	clock.SetTime(time.Now().Add(2 * time.Hour))
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetContext(ctx, "/search", nil); !errors.Is(err, ErrBudgetExhausted) {
+			t.Fatalf("Client.GetContext() error = %v, want %v", err, ErrBudgetExhausted)
+		}
+	}
+
+	if limiter.Limit() != 10 || limiter.Inflight() != 0 {
+		t.Errorf("AdaptiveLimiter limit = %d with %d inflight, want 10 with 0", limiter.Limit(), limiter.Inflight())
+	}
+}
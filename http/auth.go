@@ -0,0 +1,274 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// errAuthenticationFailed wraps errors returned by an Authenticator
+var errAuthenticationFailed = errors.New("failed to authenticate request")
+
+// Authenticator adds credentials to outbound requests
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts an ordinary function to Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls f(req)
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// WithAuth authenticates every request attempt with the authenticator
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.use(stageAuth, AuthMiddleware(auth))
+	}
+}
+
+// AuthMiddleware returns middleware that authenticates a copy of each request.
+// Responses refer to the original request, so credentials never reach HTTPError.
+func AuthMiddleware(auth Authenticator) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			authenticated := req.Clone(req.Context())
+			if err := auth.Authenticate(authenticated); err != nil {
+				closeRequestBody(req)
+				return nil, fmt.Errorf("%w: %w", errAuthenticationFailed, err)
+			}
+
+			resp, err := next.RoundTrip(authenticated)
+			if err != nil {
+				return resp, err
+			}
+
+			if resp.StatusCode == http.StatusUnauthorized {
+				if invalidator, ok := auth.(interface{ Invalidate() }); ok {
+					invalidator.Invalidate()
+				}
+			}
+			// Keep credentials added to the URL or headers out of errors and logs
+			resp.Request = req
+			return resp, nil
+		})
+	}
+}
+
+// BearerToken authenticates with a static bearer token
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKeyHeader authenticates with an API key sent in a header
+func APIKeyHeader(header, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
+
+// APIKeyQuery authenticates with an API key sent as a query parameter
+func APIKeyQuery(param, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		query := req.URL.Query()
+		query.Set(param, key)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	})
+}
+
+// BasicAuth authenticates with HTTP basic credentials
+func BasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// OAuth2Config configures the OAuth2 client credentials flow
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// RefreshBefore refreshes the token this long before it expires,
+	// capped at half the token lifetime
+	RefreshBefore time.Duration
+	// HTTPClient fetches tokens, defaults to a client with a 30 second timeout.
+	// Fetches are shared by concurrent callers and are not canceled with any of them;
+	// they are bounded by the client timeout, or 30 seconds if it has none.
+	HTTPClient *http.Client
+	// TimeProvider supplies the current time, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+}
+
+// OAuth2ClientCredentials authenticates with tokens from the client credentials flow.
+// Tokens are cached and concurrent refreshes are collapsed into a single fetch.
+type OAuth2ClientCredentials struct {
+	config    OAuth2Config
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	inflight  *tokenFetch
+}
+
+// defaultTokenTimeout bounds token fetches
+const defaultTokenTimeout = 30 * time.Second
+
+// tokenFetch is a token request shared by concurrent callers
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// tokenResponse is the token endpoint response body
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewOAuth2ClientCredentials creates a new OAuth2ClientCredentials authenticator
+func NewOAuth2ClientCredentials(config OAuth2Config) *OAuth2ClientCredentials {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = 30 * time.Second
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: defaultTokenTimeout}
+	}
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+
+	return &OAuth2ClientCredentials{config: config}
+}
+
+// Authenticate sets the bearer token on the request
+func (a *OAuth2ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := a.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns a cached token or fetches a new one
+func (a *OAuth2ClientCredentials) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	if a.token != "" && a.config.TimeProvider.Now().Before(a.refreshAt) {
+		token := a.token
+		a.mu.Unlock()
+		return token, nil
+	}
+
+	fetch := a.inflight
+	leader := fetch == nil
+	if leader {
+		fetch = &tokenFetch{done: make(chan struct{})}
+		a.inflight = fetch
+	}
+	a.mu.Unlock()
+
+	if leader {
+		// The fetch is shared, so it must outlive the caller that started it
+		go func() {
+			timeout := a.config.HTTPClient.Timeout
+			if timeout <= 0 {
+				timeout = defaultTokenTimeout
+			}
+			fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+			defer cancel()
+
+			fetch.token, fetch.err = a.fetch(fetchCtx)
+
+			a.mu.Lock()
+			a.inflight = nil
+			a.mu.Unlock()
+			close(fetch.done)
+		}()
+	}
+
+	select {
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token so the next request fetches a new one
+func (a *OAuth2ClientCredentials) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+	a.refreshAt = time.Time{}
+}
+
+// fetch requests a new token from the token endpoint and caches it
+func (a *OAuth2ClientCredentials) fetch(ctx context.Context) (string, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(a.config.ClientSecret))
+
+	resp, err := a.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch token: %w", err)
+	}
+	if !isSuccess(resp.StatusCode) {
+		return "", NewHTTPError(resp)
+	}
+
+	var body tokenResponse
+	if err := ParseJSONResponse(resp, &body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Without expires_in the token is reused until the upstream rejects it
+	lifetime := 24 * time.Hour
+	if body.ExpiresIn > 0 {
+		lifetime = time.Duration(body.ExpiresIn) * time.Second
+	}
+
+	// Short-lived tokens would otherwise be refetched on every request
+	margin := a.config.RefreshBefore
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+
+	a.token = body.AccessToken
+	a.refreshAt = a.config.TimeProvider.Now().Add(lifetime - margin)
+
+	return a.token, nil
+}
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_StaticAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  Authenticator
+		check func(r *http.Request) bool
+	}{
+		{"bearer", BearerToken("secret"), func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer secret"
+		}},
+		{"api key header", APIKeyHeader("X-Api-Key", "secret"), func(r *http.Request) bool {
+			return r.Header.Get("X-Api-Key") == "secret"
+		}},
+		{"api key query", APIKeyQuery("token", "secret"), func(r *http.Request) bool {
+			return r.URL.Query().Get("token") == "secret" && r.URL.Query().Get("origin") == "MOW"
+		}},
+		{"basic", BasicAuth("bot", "secret"), func(r *http.Request) bool {
+			user, pass, ok := r.BasicAuth()
+			return ok && user == "bot" && pass == "secret"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.check(r) {
+					t.Errorf("Request missing credentials: %v %v", r.URL, r.Header)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, WithAuth(tt.auth))
+			resp, err := client.Get("/flights?origin=MOW", nil)
+			if err != nil {
+				t.Fatalf("Client.Get() error = %v", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func newTokenServer(t *testing.T, fetches *int32, expiresIn int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "bot" || pass != "secret" {
+			t.Errorf("Token request credentials = %v/%v, want bot/secret", user, pass)
+		}
+		if r.FormValue("grant_type") != "client_credentials" {
+			t.Errorf("Token request grant_type = %v, want client_credentials", r.FormValue("grant_type"))
+		}
+
+		n := atomic.AddInt32(fetches, 1)
+		time.Sleep(20 * time.Millisecond)
+		WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+}
+
+func TestOAuth2ClientCredentials_SingleFlight(t *testing.T) {
+	var fetches int32
+	tokenServer := newTokenServer(t, &fetches, 3600)
+	defer tokenServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-1" {
+			t.Errorf("Authorization = %v, want Bearer token-1", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	auth := NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "bot",
+		ClientSecret: "secret",
+	})
+	client := NewClient(server.URL, WithAuth(auth))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("/test", nil)
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("Token fetches = %d, want 1", fetches)
+	}
+}
+
+func TestOAuth2ClientCredentials_RefreshBeforeExpiry(t *testing.T) {
+	var fetches int32
+	tokenServer := newTokenServer(t, &fetches, 300)
+	defer tokenServer.Close()
+
+	clock := newFakeClock()
+	auth := NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:      tokenServer.URL,
+		ClientID:      "bot",
+		ClientSecret:  "secret",
+		RefreshBefore: time.Minute,
+		TimeProvider:  clock,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	clock.SetTime(clock.Now().Add(3 * time.Minute))
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("Token fetches before refresh window = %d, want 1", fetches)
+	}
+
+	clock.SetTime(clock.Now().Add(time.Minute))
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Token fetches inside refresh window = %d, want 2", fetches)
+	}
+	if req.Header.Get("Authorization") != "Bearer token-2" {
+		t.Errorf("Authorization = %v, want Bearer token-2", req.Header.Get("Authorization"))
+	}
+}
+
+func TestOAuth2ClientCredentials_InvalidatesOnUnauthorized(t *testing.T) {
+	var fetches int32
+	tokenServer := newTokenServer(t, &fetches, 3600)
+	defer tokenServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	auth := NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "bot",
+		ClientSecret: "secret",
+	})
+	client := NewClient(server.URL, WithAuth(auth))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("/test", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if fetches != 2 {
+		t.Errorf("Token fetches = %d, want 2", fetches)
+	}
+}
+
+func TestOAuth2ClientCredentials_FetchOutlivesCanceledLeader(t *testing.T) {
+	var fetches int32
+	tokenServer := newTokenServer(t, &fetches, 3600)
+	defer tokenServer.Close()
+
+	auth := NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "bot",
+		ClientSecret: "secret",
+	})
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := auth.Token(leaderCtx)
+		leaderDone <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("Token() leader error = %v, want %v", err, context.Canceled)
+	}
+
+	token, err := auth.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() follower error = %v", err)
+	}
+	if token != "token-1" || fetches != 1 {
+		t.Errorf("Token() = %v after %d fetches, want token-1 after 1", token, fetches)
+	}
+}
+
+func TestOAuth2ClientCredentials_ShortLivedToken(t *testing.T) {
+	var fetches int32
+	tokenServer := newTokenServer(t, &fetches, 20)
+	defer tokenServer.Close()
+
+	clock := newFakeClock()
+	auth := NewOAuth2ClientCredentials(OAuth2Config{
+		TokenURL:      tokenServer.URL,
+		ClientID:      "bot",
+		ClientSecret:  "secret",
+		RefreshBefore: time.Minute,
+		TimeProvider:  clock,
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := auth.Token(context.Background()); err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Token fetches for a 20s token = %d, want 1", fetches)
+	}
+
+	clock.SetTime(clock.Now().Add(10 * time.Second))
+	if _, err := auth.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Token fetches at half lifetime = %d, want 2", fetches)
+	}
+}
+
+func TestClient_APIKeyQueryNotInHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAuth(APIKeyQuery("api_key", "SECRET")), WithHTTPErrors())
+
+	_, err := client.Get("/flights?origin=MOW", nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Client.Get() error = %v, want *HTTPError", err)
+	}
+	if strings.Contains(httpErr.Error(), "SECRET") || !strings.Contains(httpErr.URL, "origin=MOW") {
+		t.Errorf("HTTPError.URL = %v, want the URL without the API key", httpErr.URL)
+	}
+}
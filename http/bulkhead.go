@@ -0,0 +1,254 @@
+package http
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBulkheadFull is returned when a bulkhead has no free slot and its queue is full or timed out
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// BulkheadLimit caps concurrent requests for one compartment
+type BulkheadLimit struct {
+	// MaxConcurrent is the number of requests in flight, 0 or less means unlimited
+	MaxConcurrent int
+	// MaxQueue is the number of requests waiting for a slot, 0 rejects immediately when full
+	MaxQueue int
+}
+
+// BulkheadConfig configures a Bulkhead
+type BulkheadConfig struct {
+	// Limit applies to every key without an override
+	Limit BulkheadLimit
+	// Limits overrides Limit for specific keys
+	Limits map[string]BulkheadLimit
+	// QueueTimeout bounds how long a request waits for a slot, 0 waits until the context is done
+	QueueTimeout time.Duration
+	// KeyFunc selects the compartment for a request, defaults to KeyByHost
+	KeyFunc func(req *http.Request) string
+	// Clock waits out QueueTimeout, defaults to the system clock
+	Clock Clock
+}
+
+// BulkheadStats describes the current load of a compartment
+type BulkheadStats struct {
+	Active        int
+	Queued        int
+	MaxConcurrent int
+	MaxQueue      int
+}
+
+// Utilization returns the fraction of slots in use
+func (s BulkheadStats) Utilization() float64 {
+	if s.MaxConcurrent <= 0 {
+		return 0
+	}
+	return float64(s.Active) / float64(s.MaxConcurrent)
+}
+
+// Bulkhead caps in-flight requests per compartment so one slow upstream cannot exhaust the client
+type Bulkhead struct {
+	config       BulkheadConfig
+	mu           sync.Mutex
+	compartments map[string]*compartment
+}
+
+// compartment tracks the slots and waiters of one key
+type compartment struct {
+	limit   BulkheadLimit
+	active  int
+	waiters *list.List
+}
+
+// NewBulkhead creates a new Bulkhead
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByHost()
+	}
+	if config.Clock == nil {
+		config.Clock = systemClock
+	}
+
+	return &Bulkhead{
+		config:       config,
+		compartments: make(map[string]*compartment),
+	}
+}
+
+// WithBulkhead caps concurrent requests with the bulkhead
+func WithBulkhead(bulkhead *Bulkhead) Option {
+	return func(c *Client) {
+		c.use(stageGuard, bulkhead.Middleware())
+	}
+}
+
+// KeyByHost returns a key function using the request host
+func KeyByHost() func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.URL.Host
+	}
+}
+
+// Acquire takes a slot for the key, queueing if none is free.
+// The returned function releases the slot and must be called exactly once.
+func (b *Bulkhead) Acquire(ctx context.Context, key string) (func(), error) {
+	b.mu.Lock()
+	c := b.compartment(key)
+	if c.limit.MaxConcurrent <= 0 {
+		b.mu.Unlock()
+		return func() {}, nil
+	}
+	if c.active < c.limit.MaxConcurrent {
+		c.active++
+		b.mu.Unlock()
+		return b.releaser(c), nil
+	}
+	if c.waiters.Len() >= c.limit.MaxQueue {
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrBulkheadFull, key)
+	}
+
+	ready := make(chan struct{})
+	waiter := c.waiters.PushBack(ready)
+	b.mu.Unlock()
+
+	var timeout <-chan struct{}
+	if b.config.QueueTimeout > 0 {
+		waitCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		expired := make(chan struct{})
+		go func() {
+			if b.config.Clock.Sleep(waitCtx, b.config.QueueTimeout) == nil {
+				close(expired)
+			}
+		}()
+		timeout = expired
+	}
+
+	select {
+	case <-ready:
+		return b.releaser(c), nil
+	case <-ctx.Done():
+		b.abandon(c, waiter, ready)
+		return nil, ctx.Err()
+	case <-timeout:
+		b.abandon(c, waiter, ready)
+		return nil, fmt.Errorf("%w: %s: queued for %v", ErrBulkheadFull, key, b.config.QueueTimeout)
+	}
+}
+
+// Stats returns the load of the compartment for the key
+func (b *Bulkhead) Stats(key string) BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.compartment(key).stats()
+}
+
+// Utilization returns the load of every compartment that has been used
+func (b *Bulkhead) Utilization() map[string]BulkheadStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make(map[string]BulkheadStats, len(b.compartments))
+	for key, c := range b.compartments {
+		result[key] = c.stats()
+	}
+	return result
+}
+
+// Middleware returns a middleware holding a slot until the response body is closed
+func (b *Bulkhead) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			release, err := b.Acquire(req.Context(), b.config.KeyFunc(req))
+			if err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				release()
+				return nil, err
+			}
+
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		})
+	}
+}
+
+// compartment returns the compartment for the key; callers must hold b.mu
+func (b *Bulkhead) compartment(key string) *compartment {
+	c, ok := b.compartments[key]
+	if !ok {
+		limit, ok := b.config.Limits[key]
+		if !ok {
+			limit = b.config.Limit
+		}
+		c = &compartment{limit: limit, waiters: list.New()}
+		b.compartments[key] = c
+	}
+	return c
+}
+
+// releaser returns a function that frees the slot or hands it to the next waiter
+func (b *Bulkhead) releaser(c *compartment) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if front := c.waiters.Front(); front != nil {
+				c.waiters.Remove(front)
+				close(front.Value.(chan struct{}))
+				return
+			}
+			c.active--
+		})
+	}
+}
+
+// abandon removes a waiter that gave up, passing on a slot handed to it meanwhile
+func (b *Bulkhead) abandon(c *compartment, waiter *list.Element, ready chan struct{}) {
+	b.mu.Lock()
+	select {
+	case <-ready:
+		b.mu.Unlock()
+		b.releaser(c)()
+	default:
+		c.waiters.Remove(waiter)
+		b.mu.Unlock()
+	}
+}
+
+// stats returns the compartment load; callers must hold the bulkhead lock
+func (c *compartment) stats() BulkheadStats {
+	return BulkheadStats{
+		Active:        c.active,
+		Queued:        c.waiters.Len(),
+		MaxConcurrent: c.limit.MaxConcurrent,
+		MaxQueue:      c.limit.MaxQueue,
+	}
+}
+
+// releaseOnClose releases a bulkhead slot once the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the body and releases the slot
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBulkhead_RejectsWhenFull(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadConfig{Limit: BulkheadLimit{MaxConcurrent: 1}})
+
+	release, err := bulkhead.Acquire(context.Background(), "search")
+	if err != nil {
+		t.Fatalf("Bulkhead.Acquire() error = %v", err)
+	}
+
+	if _, err := bulkhead.Acquire(context.Background(), "search"); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Bulkhead.Acquire() error = %v, want %v", err, ErrBulkheadFull)
+	}
+	if _, err := bulkhead.Acquire(context.Background(), "pricing"); err != nil {
+		t.Errorf("Bulkhead.Acquire() other key error = %v, want nil", err)
+	}
+
+	release()
+	release()
+	if stats := bulkhead.Stats("search"); stats.Active != 0 {
+		t.Errorf("Bulkhead.Stats() active = %d after double release, want 0", stats.Active)
+	}
+}
+
+func TestBulkhead_QueueHandOff(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadConfig{Limit: BulkheadLimit{MaxConcurrent: 1, MaxQueue: 1}})
+
+	release, _ := bulkhead.Acquire(context.Background(), "search")
+
+	acquired := make(chan func())
+	go func() {
+		next, err := bulkhead.Acquire(context.Background(), "search")
+		if err != nil {
+			t.Errorf("Bulkhead.Acquire() queued error = %v", err)
+		}
+		acquired <- next
+	}()
+
+	for bulkhead.Stats("search").Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := bulkhead.Acquire(context.Background(), "search"); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Bulkhead.Acquire() with full queue error = %v, want %v", err, ErrBulkheadFull)
+	}
+
+	stats := bulkhead.Stats("search")
+	if stats.Utilization() != 1 {
+		t.Errorf("BulkheadStats.Utilization() = %v, want 1", stats.Utilization())
+	}
+
+	release()
+	(<-acquired)()
+
+	if stats := bulkhead.Stats("search"); stats.Active != 0 || stats.Queued != 0 {
+		t.Errorf("Bulkhead.Stats() = %+v, want empty", stats)
+	}
+}
+
+func TestBulkhead_QueueTimeout(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadConfig{
+		Limit:        BulkheadLimit{MaxConcurrent: 1, MaxQueue: 5},
+		QueueTimeout: time.Hour,
+		Clock:        newFakeClock(),
+	})
+
+	release, _ := bulkhead.Acquire(context.Background(), "search")
+	defer release()
+
+	if _, err := bulkhead.Acquire(context.Background(), "search"); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Bulkhead.Acquire() error = %v, want %v", err, ErrBulkheadFull)
+	}
+	if stats := bulkhead.Stats("search"); stats.Queued != 0 {
+		t.Errorf("Bulkhead.Stats() queued = %d after timeout, want 0", stats.Queued)
+	}
+}
+
+func TestClient_WithBulkhead(t *testing.T) {
+	var hits int32
+	server := newCountingServer(&hits, http.StatusOK)
+	defer server.Close()
+
+	bulkhead := NewBulkhead(BulkheadConfig{Limit: BulkheadLimit{MaxConcurrent: 1}})
+	client := NewClient(server.URL, WithBulkhead(bulkhead), WithRetryPolicy(DefaultRetryPolicy()), WithClock(newFakeClock()))
+
+	resp, err := client.Get("/search", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+
+	host := mustParseURL(t, server.URL).Host
+	if stats := bulkhead.Utilization()[host]; stats.Active != 1 {
+		t.Errorf("Bulkhead.Utilization() active = %d while body open, want 1", stats.Active)
+	}
+
+	if _, err := client.Get("/search", nil); !errors.Is(err, ErrBulkheadFull) {
+		t.Errorf("Client.Get() error = %v, want %v", err, ErrBulkheadFull)
+	}
+	if hits != 1 {
+		t.Errorf("Server hits = %d, want 1 without retrying rejected request", hits)
+	}
+
+	resp.Body.Close()
+	if stats := bulkhead.Stats(host); stats.Active != 0 {
+		t.Errorf("Bulkhead.Stats() active = %d after close, want 0", stats.Active)
+	}
+}
+
+func TestBulkhead_ContextCanceled(t *testing.T) {
+	bulkhead := NewBulkhead(BulkheadConfig{Limit: BulkheadLimit{MaxConcurrent: 1, MaxQueue: 1}})
+	release, _ := bulkhead.Acquire(context.Background(), "search")
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := bulkhead.Acquire(ctx, "search"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Bulkhead.Acquire() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,334 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// CachedResponse is a response stored in a Cache
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+	Expires    time.Time
+	// Vary holds the request headers named by the response Vary header,
+	// which a request must match to be served this entry
+	Vary http.Header
+}
+
+// Cache stores responses by key; implementations must be safe for concurrent use
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+	Delete(key string)
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entries
+type LRUCache struct {
+	capacity int
+	mu       sync.Mutex
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// lruItem is a key and value stored in the LRU list
+type lruItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+// NewLRUCache creates a new LRUCache holding up to capacity entries
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1000 // Default capacity
+	}
+	return &LRUCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for the key and marks it as recently used
+func (c *LRUCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.items[key]
+	if !exists {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruItem).entry, true
+}
+
+// Set stores the entry, evicting the least recently used one if full
+func (c *LRUCache) Set(key string, entry *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		elem.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: entry})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Delete removes the entry for the key
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of stored entries
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// CacheConfig configures the HTTP response cache
+type CacheConfig struct {
+	// Cache stores responses, defaults to an LRUCache
+	Cache Cache
+	// MaxBodyBytes is the largest response body that is cached, defaults to 1 MB
+	MaxBodyBytes int64
+	// TimeProvider supplies the current time, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+}
+
+// WithCache caches GET responses according to Cache-Control, ETag and Last-Modified
+func WithCache(config CacheConfig) Option {
+	return func(c *Client) {
+		c.use(stageCache, CacheMiddleware(config))
+	}
+}
+
+// CacheMiddleware returns middleware that serves and revalidates cached GET responses
+func CacheMiddleware(config CacheConfig) Middleware {
+	if config.Cache == nil {
+		config.Cache = NewLRUCache(0)
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next.RoundTrip(req)
+			}
+
+			requestDirectives := parseCacheControl(req.Header.Get("Cache-Control"))
+			if _, noStore := requestDirectives["no-store"]; noStore {
+				return next.RoundTrip(req)
+			}
+
+			key := req.URL.String()
+			entry, cached := config.Cache.Get(key)
+			if cached && !entry.matches(req) {
+				// Stored for a request with different Vary headers, e.g. another user
+				entry, cached = nil, false
+			}
+			_, noCache := requestDirectives["no-cache"]
+			if cached && !noCache && config.TimeProvider.Now().Before(entry.Expires) {
+				return entry.response(req), nil
+			}
+
+			outbound := req
+			if cached {
+				outbound = req.Clone(req.Context())
+				if etag := entry.Header.Get("ETag"); etag != "" {
+					outbound.Header.Set("If-None-Match", etag)
+				}
+				if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+					outbound.Header.Set("If-Modified-Since", lastModified)
+				}
+			}
+
+			resp, err := next.RoundTrip(outbound)
+			if err != nil {
+				return nil, err
+			}
+			now := config.TimeProvider.Now()
+
+			if cached && resp.StatusCode == http.StatusNotModified {
+				drainBody(resp)
+				refreshed := *entry
+				refreshed.Header = entry.Header.Clone()
+				for name, values := range resp.Header {
+					refreshed.Header[name] = values
+				}
+				refreshed.StoredAt = now
+				refreshed.Expires = now.Add(freshness(refreshed.Header, now))
+				config.Cache.Set(key, &refreshed)
+				return refreshed.response(req), nil
+			}
+
+			if !isCacheable(req, resp) {
+				if resp.StatusCode == http.StatusOK {
+					config.Cache.Delete(key)
+				}
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(io.LimitReader(resp.Body, config.MaxBodyBytes+1))
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			if int64(len(body)) > config.MaxBodyBytes {
+				// Too large to cache: hand back the buffered prefix followed by the rest
+				resp.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+				return resp, nil
+			}
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			config.Cache.Set(key, &CachedResponse{
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header.Clone(),
+				Body:       body,
+				StoredAt:   now,
+				Expires:    now.Add(freshness(resp.Header, now)),
+				Vary:       varyHeaders(req, resp.Header),
+			})
+			return resp, nil
+		})
+	}
+}
+
+// response builds an http.Response from the cached entry
+func (e *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// matches reports whether the request carries the Vary headers the entry was stored with
+func (e *CachedResponse) matches(req *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// varyHeaders returns the request headers named by the Vary response header
+func varyHeaders(req *http.Request, header http.Header) http.Header {
+	vary := http.Header{}
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+	return vary
+}
+
+// isCacheable reports whether the response to the request may be stored
+func isCacheable(req *http.Request, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if _, noStore := directives["no-store"]; noStore {
+		return false
+	}
+	// Responses to authenticated requests are private unless marked public
+	if _, public := directives["public"]; !public && req.Header.Get("Authorization") != "" {
+		return false
+	}
+
+	_, hasMaxAge := directives["max-age"]
+	return hasMaxAge ||
+		resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != ""
+}
+
+// freshness returns how long a response stays fresh from its headers
+func freshness(header http.Header, now time.Time) time.Duration {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, noCache := directives["no-cache"]; noCache {
+		return 0
+	}
+
+	if maxAge, exists := directives["max-age"]; exists {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		age, _ := strconv.Atoi(header.Get("Age"))
+		if remaining := seconds - age; remaining > 0 {
+			return time.Duration(remaining) * time.Second
+		}
+		return 0
+	}
+
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+		base := now
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			base = date
+		}
+		if lifetime := expires.Sub(base); lifetime > 0 {
+			return lifetime
+		}
+	}
+
+	return 0
+}
+
+// parseCacheControl splits a Cache-Control header into lowercase directives
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
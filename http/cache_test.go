@@ -0,0 +1,224 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	return string(body)
+}
+
+func TestCache_ServesFreshResponse(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"airports":["SVO","LED"]}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: clock}))
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("/airports", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		if body := readBody(t, resp); body != `{"airports":["SVO","LED"]}` {
+			t.Errorf("Client.Get() body = %v", body)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Upstream hits while fresh = %d, want 1", hits)
+	}
+
+	clock.SetTime(clock.Now().Add(61 * time.Second))
+	resp, err := client.Get("/airports", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	readBody(t, resp)
+
+	if hits != 2 {
+		t.Errorf("Upstream hits after expiry = %d, want 2", hits)
+	}
+}
+
+func TestCache_RevalidatesWithETag(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write([]byte("airlines"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: newFakeClock()}))
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("/airlines", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+		if body := readBody(t, resp); body != "airlines" {
+			t.Errorf("Client.Get() body = %v, want airlines", body)
+		}
+	}
+
+	if full != 1 || notModified != 2 {
+		t.Errorf("Upstream full = %d, not modified = %d, want 1 and 2", full, notModified)
+	}
+}
+
+func TestCache_RevalidatesWithLastModified(t *testing.T) {
+	lastModified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	var conditional int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Cache-Control", "max-age=10")
+		w.Write([]byte("cities"))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: clock}))
+
+	resp, err := client.Get("/cities", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	readBody(t, resp)
+
+	clock.SetTime(clock.Now().Add(11 * time.Second))
+	resp, err = client.Get("/cities", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	if body := readBody(t, resp); body != "cities" {
+		t.Errorf("Client.Get() body = %v, want cities", body)
+	}
+	if conditional != 1 {
+		t.Errorf("Conditional requests = %d, want 1", conditional)
+	}
+}
+
+func TestCache_SkipsNoStore(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("prices"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: newFakeClock()}))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("/prices", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		readBody(t, resp)
+	}
+	if hits != 2 {
+		t.Errorf("Upstream hits = %d, want 2", hits)
+	}
+}
+
+func TestLRUCache_Evicts(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", &CachedResponse{})
+	cache.Set("b", &CachedResponse{})
+	cache.Get("a")
+	cache.Set("c", &CachedResponse{})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("LRUCache.Get(b) found evicted entry")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("LRUCache.Get(a) missing recently used entry")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("LRUCache.Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestCache_KeysOnVaryHeaders(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("Vary", "Authorization, Accept-Language")
+		w.Write([]byte("bookings of " + r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: newFakeClock()}))
+	get := func(user string) string {
+		resp, err := client.Get("/bookings", map[string]string{"Authorization": user})
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		return readBody(t, resp)
+	}
+
+	get("alice")
+	if body := get("bob"); body != "bookings of bob" {
+		t.Errorf("Client.Get() for bob = %v, want bookings of bob", body)
+	}
+	if body := get("bob"); body != "bookings of bob" || hits != 2 {
+		t.Errorf("Client.Get() repeated for bob = %v after %d hits, want cached after 2", body, hits)
+	}
+}
+
+func TestCache_SkipsPrivateAuthorizedResponses(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("bookings of " + r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCache(CacheConfig{TimeProvider: newFakeClock()}))
+
+	for _, user := range []string{"alice", "bob"} {
+		resp, err := client.Get("/bookings", map[string]string{"Authorization": user})
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		if body := readBody(t, resp); body != "bookings of "+user {
+			t.Errorf("Client.Get() for %s = %v", user, body)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("Upstream hits = %d, want 2", hits)
+	}
+}
@@ -0,0 +1,300 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// ErrCircuitOpen is returned when a request is rejected by an open circuit
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit for a single host
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the cooldown expires
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through
+	CircuitHalfOpen
+)
+
+// String returns the state name
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures trips the circuit after this many failures in a row, 0 disables
+	ConsecutiveFailures int
+	// FailureRatio trips the circuit when the failure ratio within Window reaches it, 0 disables
+	FailureRatio float64
+	// MinRequests is the number of requests in Window before FailureRatio applies
+	MinRequests int
+	// Window is the period over which the failure ratio is counted
+	Window time.Duration
+	// Cooldown is how long the circuit stays open before probing
+	Cooldown time.Duration
+	// HalfOpenProbes is the number of successful probes required to close the circuit
+	HalfOpenProbes int
+	// IsFailure classifies an attempt outcome, defaults to errors and 5xx responses
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called after a host's circuit changes state
+	OnStateChange func(host string, from, to CircuitState)
+	// TimeProvider supplies the current time, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+}
+
+// DefaultCircuitBreakerConfig returns a configuration suitable for most upstreams
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		ConsecutiveFailures: 5,
+		FailureRatio:        0.5,
+		MinRequests:         20,
+		Window:              time.Minute,
+		Cooldown:            30 * time.Second,
+		HalfOpenProbes:      1,
+	}
+}
+
+// CircuitBreaker tracks upstream health and rejects requests to failing hosts
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*circuit
+}
+
+// circuit holds the breaker state for a single host
+type circuit struct {
+	state          CircuitState
+	consecutive    int
+	requests       int
+	failures       int
+	windowStart    time.Time
+	openedAt       time.Time
+	probesInFlight int
+	probeSuccesses int
+	// generation changes on every state transition so outcomes of requests
+	// admitted in an earlier state are ignored
+	generation uint64
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		hosts:  make(map[string]*circuit),
+	}
+}
+
+// WithCircuitBreaker guards requests with the circuit breaker
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.use(stageGuard, breaker.Middleware())
+	}
+}
+
+// State returns the current circuit state for the host
+func (b *CircuitBreaker) State(host string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cb, exists := b.hosts[host]; exists {
+		return cb.state
+	}
+	return CircuitClosed
+}
+
+// Middleware returns middleware that applies the breaker per request host
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+			generation, ok := b.allow(host)
+			if !ok {
+				closeRequestBody(req)
+				return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil && (req.Context().Err() != nil || isLocalFailure(err)) {
+				// Caller cancellation and local failures say nothing about upstream health
+				b.release(host, generation)
+				return resp, err
+			}
+
+			b.record(host, generation, b.config.IsFailure(resp, err))
+			return resp, err
+		})
+	}
+}
+
+// allow reports whether a request to the host may proceed and returns the circuit
+// generation the request was admitted in
+func (b *CircuitBreaker) allow(host string) (uint64, bool) {
+	b.mu.Lock()
+	now := b.config.TimeProvider.Now()
+	cb := b.circuit(host, now)
+	from := cb.state
+
+	allowed := true
+	switch cb.state {
+	case CircuitClosed:
+		if b.config.Window > 0 && now.Sub(cb.windowStart) >= b.config.Window {
+			cb.resetCounts(now)
+		}
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) < b.config.Cooldown {
+			allowed = false
+			break
+		}
+		cb.state = CircuitHalfOpen
+		cb.probesInFlight = 0
+		cb.probeSuccesses = 0
+		cb.generation++
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probesInFlight+cb.probeSuccesses >= b.config.HalfOpenProbes {
+			allowed = false
+			break
+		}
+		cb.probesInFlight++
+	}
+
+	to := cb.state
+	generation := cb.generation
+	b.mu.Unlock()
+
+	b.notify(host, from, to)
+	return generation, allowed
+}
+
+// record updates the host circuit with the outcome of an attempt admitted in generation
+func (b *CircuitBreaker) record(host string, generation uint64, failed bool) {
+	b.mu.Lock()
+	now := b.config.TimeProvider.Now()
+	cb := b.circuit(host, now)
+	if cb.generation != generation {
+		// The circuit changed state since the request was admitted
+		b.mu.Unlock()
+		return
+	}
+	from := cb.state
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.requests++
+		if failed {
+			cb.failures++
+			cb.consecutive++
+		} else {
+			cb.consecutive = 0
+		}
+		if b.shouldTrip(cb) {
+			cb.trip(now)
+		}
+	case CircuitHalfOpen:
+		cb.probesInFlight--
+		if failed {
+			cb.trip(now)
+			break
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= b.config.HalfOpenProbes {
+			cb.state = CircuitClosed
+			cb.generation++
+			cb.resetCounts(now)
+		}
+	}
+
+	to := cb.state
+	b.mu.Unlock()
+
+	b.notify(host, from, to)
+}
+
+// release frees a half-open probe slot admitted in generation without recording an outcome
+func (b *CircuitBreaker) release(host string, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, exists := b.hosts[host]
+	if exists && cb.generation == generation && cb.state == CircuitHalfOpen && cb.probesInFlight > 0 {
+		cb.probesInFlight--
+	}
+}
+
+// shouldTrip reports whether the closed circuit has crossed a threshold
+func (b *CircuitBreaker) shouldTrip(cb *circuit) bool {
+	if b.config.ConsecutiveFailures > 0 && cb.consecutive >= b.config.ConsecutiveFailures {
+		return true
+	}
+
+	if b.config.FailureRatio > 0 && cb.requests >= b.config.MinRequests && cb.requests > 0 {
+		return float64(cb.failures)/float64(cb.requests) >= b.config.FailureRatio
+	}
+
+	return false
+}
+
+// circuit returns the host circuit, creating it if needed; b.mu must be held
+func (b *CircuitBreaker) circuit(host string, now time.Time) *circuit {
+	cb, exists := b.hosts[host]
+	if !exists {
+		cb = &circuit{windowStart: now}
+		b.hosts[host] = cb
+	}
+	return cb
+}
+
+// notify reports a state transition to the configured callback
+func (b *CircuitBreaker) notify(host string, from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(host, from, to)
+	}
+}
+
+// trip opens the circuit
+func (cb *circuit) trip(now time.Time) {
+	cb.state = CircuitOpen
+	cb.openedAt = now
+	cb.generation++
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+}
+
+// resetCounts starts a new counting window
+func (cb *circuit) resetCounts(now time.Time) {
+	cb.consecutive = 0
+	cb.requests = 0
+	cb.failures = 0
+	cb.windowStart = now
+}
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_TripsOnConsecutiveFailures(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultCircuitBreakerConfig()
+	config.ConsecutiveFailures = 3
+	config.TimeProvider = newFakeClock()
+	breaker := NewCircuitBreaker(config)
+	client := NewClient(server.URL, WithCircuitBreaker(breaker))
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get("/test", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() call %d error = %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.Get("/test", nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Client.Get() error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Upstream attempts = %d, want 3", attempts)
+	}
+
+	host := mustParseURL(t, server.URL).Host
+	if state := breaker.State(host); state != CircuitOpen {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", state, CircuitOpen)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	var transitions []string
+	config := DefaultCircuitBreakerConfig()
+	config.ConsecutiveFailures = 1
+	config.Cooldown = 10 * time.Second
+	config.TimeProvider = clock
+	config.OnStateChange = func(host string, from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+	breaker := NewCircuitBreaker(config)
+	client := NewClient(server.URL, WithCircuitBreaker(breaker))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get("/test", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Client.Get() during cooldown error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	clock.SetTime(clock.Now().Add(10 * time.Second))
+
+	resp, err = client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() probe error = %v", err)
+	}
+	resp.Body.Close()
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("State transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("State transition %d = %v, want %v", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_FailureRatio(t *testing.T) {
+	config := CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		Window:       time.Minute,
+		Cooldown:     time.Minute,
+		TimeProvider: newFakeClock(),
+	}
+	breaker := NewCircuitBreaker(config)
+
+	outcomes := []bool{false, true, false, true}
+	for _, failed := range outcomes {
+		generation, ok := breaker.allow("api.example.com")
+		if !ok {
+			t.Fatal("CircuitBreaker.allow() = false before threshold")
+		}
+		breaker.record("api.example.com", generation, failed)
+	}
+
+	if state := breaker.State("api.example.com"); state != CircuitOpen {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", state, CircuitOpen)
+	}
+	if state := breaker.State("other.example.com"); state != CircuitClosed {
+		t.Errorf("CircuitBreaker.State() for other host = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreaker_IgnoresStaleOutcomes(t *testing.T) {
+	clock := newFakeClock()
+	config := DefaultCircuitBreakerConfig()
+	config.ConsecutiveFailures = 1
+	config.Cooldown = 10 * time.Second
+	config.TimeProvider = clock
+	breaker := NewCircuitBreaker(config)
+	host := "api.example.com"
+
+	slow, _ := breaker.allow(host)
+	failing, _ := breaker.allow(host)
+	breaker.record(host, failing, true)
+
+	clock.SetTime(clock.Now().Add(10 * time.Second))
+	probe, ok := breaker.allow(host)
+	if !ok {
+		t.Fatal("CircuitBreaker.allow() = false after cooldown")
+	}
+
+	// A success admitted while closed must not count as the half-open probe
+	breaker.record(host, slow, false)
+	if state := breaker.State(host); state != CircuitHalfOpen {
+		t.Errorf("CircuitBreaker.State() after stale success = %v, want %v", state, CircuitHalfOpen)
+	}
+	if _, ok := breaker.allow(host); ok {
+		t.Error("CircuitBreaker.allow() = true with probe in flight, want false")
+	}
+
+	breaker.record(host, probe, false)
+	if state := breaker.State(host); state != CircuitClosed {
+		t.Errorf("CircuitBreaker.State() after probe success = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestClient_RetrySkipsOpenCircuit(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := DefaultCircuitBreakerConfig()
+	config.ConsecutiveFailures = 1
+	config.TimeProvider = newFakeClock()
+	client := NewClient(server.URL,
+		WithCircuitBreaker(NewCircuitBreaker(config)),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	if _, err := client.Get("/test", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Client.Get() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if attempts != 1 {
+		t.Errorf("Upstream attempts = %d, want 1", attempts)
+	}
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %v: %v", rawURL, err)
+	}
+	return u
+}
+
+func TestCircuitBreaker_IgnoresLocalFailures(t *testing.T) {
+	var attempts int32
+	server := newCountingServer(&attempts, http.StatusOK)
+	defer server.Close()
+
+	config := DefaultCircuitBreakerConfig()
+	config.ConsecutiveFailures = 2
+	config.TimeProvider = newFakeClock()
+	breaker := NewCircuitBreaker(config)
+	auth := AuthenticatorFunc(func(req *http.Request) error {
+		return errors.New("token endpoint unavailable")
+	})
+	client := NewClient(server.URL, WithCircuitBreaker(breaker), WithAuth(auth))
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Get("/test", nil)
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Client.Get() call %d error = %v, want authentication error", i+1, err)
+		}
+	}
+
+	host := mustParseURL(t, server.URL).Host
+	if state := breaker.State(host); state != CircuitClosed {
+		t.Errorf("CircuitBreaker.State() = %v, want %v", state, CircuitClosed)
+	}
+	if attempts != 0 {
+		t.Errorf("Upstream attempts = %d, want 0", attempts)
+	}
+}
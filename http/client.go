@@ -2,82 +2,140 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
 )
 
 // Client provides HTTP client utilities
 type Client struct {
 	httpClient *http.Client
-	baseURL    string
+	// streamClient shares the transport of httpClient without its total timeout
+	streamClient *http.Client
+	baseURL      string
+	retryPolicy  *RetryPolicy
+	clock        Clock
+	httpErrors   bool
+	transport    http.RoundTripper
+	middleware   []stagedMiddleware
+	endpoints    *EndpointPool
+
+	maxResponseBytes int64
+
+	requestIDGenerator interfaces.IDGenerator
+	requestIDHeader    string
+
+	idempotencyKeyGenerator interfaces.IDGenerator
+	idempotencyKeyHeader    string
 }
 
+// Option configures optional Client behavior
+type Option func(*Client)
+
 // NewClient creates a new HTTP client with default settings
-func NewClient(baseURL string) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: baseURL,
-	}
+func NewClient(baseURL string, opts ...Option) *Client {
+	return NewClientWithTimeout(baseURL, 30*time.Second, opts...)
 }
 
 // NewClientWithTimeout creates a new HTTP client with custom timeout
-func NewClientWithTimeout(baseURL string, timeout time.Duration) *Client {
-	return &Client{
+func NewClientWithTimeout(baseURL string, timeout time.Duration, opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		baseURL: baseURL,
+		baseURL:              baseURL,
+		clock:                systemClock,
+		requestIDHeader:      DefaultRequestIDHeader,
+		idempotencyKeyHeader: DefaultIdempotencyKeyHeader,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.httpClient.Transport = c.buildTransport()
+	c.streamClient = &http.Client{Transport: c.httpClient.Transport}
+
+	return c
 }
 
 // Get performs a GET request
 func (c *Client) Get(endpoint string, headers map[string]string) (*http.Response, error) {
-	url := c.buildURL(endpoint)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GET request: %w", err)
-	}
-
-	c.setHeaders(req, headers)
-	return c.httpClient.Do(req)
+	return c.GetContext(context.Background(), endpoint, headers)
 }
 
 // Post performs a POST request with JSON body
 func (c *Client) Post(endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
-	url := c.buildURL(endpoint)
+	return c.PostContext(context.Background(), endpoint, body, headers)
+}
 
-	var bodyReader io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal JSON body: %w", err)
-		}
-		bodyReader = bytes.NewBuffer(jsonBody)
-	}
+// Put performs a PUT request with JSON body
+func (c *Client) Put(endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return c.PutContext(context.Background(), endpoint, body, headers)
+}
 
-	req, err := http.NewRequest(http.MethodPost, url, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create POST request: %w", err)
-	}
+// Delete performs a DELETE request
+func (c *Client) Delete(endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.DeleteContext(context.Background(), endpoint, headers)
+}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	c.setHeaders(req, headers)
+// Patch performs a PATCH request with JSON body
+func (c *Client) Patch(endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return c.PatchContext(context.Background(), endpoint, body, headers)
+}
 
-	return c.httpClient.Do(req)
+// Head performs a HEAD request
+func (c *Client) Head(endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.HeadContext(context.Background(), endpoint, headers)
 }
 
-// Put performs a PUT request with JSON body
-func (c *Client) Put(endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
-	url := c.buildURL(endpoint)
+// Options performs an OPTIONS request
+func (c *Client) Options(endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.OptionsContext(context.Background(), endpoint, headers)
+}
+
+// GetContext performs a GET request bound to the context
+func (c *Client) GetContext(ctx context.Context, endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodGet, endpoint, nil, headers)
+}
+
+// PostContext performs a POST request with JSON body bound to the context
+func (c *Client) PostContext(ctx context.Context, endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodPost, endpoint, body, headers)
+}
+
+// PutContext performs a PUT request with JSON body bound to the context
+func (c *Client) PutContext(ctx context.Context, endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodPut, endpoint, body, headers)
+}
+
+// DeleteContext performs a DELETE request bound to the context
+func (c *Client) DeleteContext(ctx context.Context, endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodDelete, endpoint, nil, headers)
+}
+
+// PatchContext performs a PATCH request with JSON body bound to the context
+func (c *Client) PatchContext(ctx context.Context, endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodPatch, endpoint, body, headers)
+}
+
+// HeadContext performs a HEAD request bound to the context
+func (c *Client) HeadContext(ctx context.Context, endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodHead, endpoint, nil, headers)
+}
+
+// OptionsContext performs an OPTIONS request bound to the context
+func (c *Client) OptionsContext(ctx context.Context, endpoint string, headers map[string]string) (*http.Response, error) {
+	return c.Do(ctx, http.MethodOptions, endpoint, nil, headers)
+}
 
+// Do performs a request with an optional JSON body bound to the context
+func (c *Client) Do(ctx context.Context, method, endpoint string, body interface{}, headers map[string]string) (*http.Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -87,9 +145,9 @@ func (c *Client) Put(endpoint string, body interface{}, headers map[string]strin
 		bodyReader = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, url, bodyReader)
+	req, err := c.newRequest(ctx, method, endpoint, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create PUT request: %w", err)
+		return nil, err
 	}
 
 	if body != nil {
@@ -97,35 +155,58 @@ func (c *Client) Put(endpoint string, body interface{}, headers map[string]strin
 	}
 	c.setHeaders(req, headers)
 
-	return c.httpClient.Do(req)
+	return c.send(req, endpoint)
 }
 
-// Delete performs a DELETE request
-func (c *Client) Delete(endpoint string, headers map[string]string) (*http.Response, error) {
-	url := c.buildURL(endpoint)
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+// newRequest creates a request for the endpoint resolved against the base URL
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.buildURL(endpoint), body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create DELETE request: %w", err)
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
 	}
+	return req, nil
+}
 
-	c.setHeaders(req, headers)
-	return c.httpClient.Do(req)
+// send dispatches a prepared request through retries and the middleware pipeline
+func (c *Client) send(req *http.Request, endpoint string) (*http.Response, error) {
+	c.setRequestID(req)
+	c.setIdempotencyKey(req)
+
+	resp, err := c.do(req, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.maxResponseBytes > 0 {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: c.maxResponseBytes}
+	}
+
+	if c.httpErrors && !isSuccess(resp.StatusCode) {
+		return nil, NewHTTPError(resp)
+	}
+
+	return resp, nil
 }
 
 // buildURL constructs the full URL
 func (c *Client) buildURL(endpoint string) string {
-	if c.baseURL == "" {
+	return resolveURL(c.baseURL, endpoint)
+}
+
+// resolveURL resolves the endpoint against the base URL
+func resolveURL(base, endpoint string) string {
+	if base == "" {
 		return endpoint
 	}
 
-	baseURL, err := url.Parse(c.baseURL)
+	baseURL, err := url.Parse(base)
 	if err != nil {
 		return endpoint
 	}
 
 	endpointURL, err := url.Parse(endpoint)
 	if err != nil {
-		return c.baseURL + endpoint
+		return base + endpoint
 	}
 
 	return baseURL.ResolveReference(endpointURL).String()
@@ -138,17 +219,13 @@ func (c *Client) setHeaders(req *http.Request, headers map[string]string) {
 	}
 }
 
-// ParseJSONResponse parses JSON response into provided struct
-func ParseJSONResponse(resp *http.Response, v interface{}) error {
+// ParseJSONResponse parses JSON response into provided struct.
+// The body size is unlimited unless set with MaxBodyBytes or the client's WithMaxResponseBytes.
+func ParseJSONResponse(resp *http.Response, v interface{}, opts ...DecodeOption) error {
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if err := json.Unmarshal(body, v); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON response: %w", err)
+	if err := decodeJSONBody(resp.Body, resp.Header.Get("Content-Type"), v, 0, opts); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -165,6 +166,58 @@ func TestClient_Delete(t *testing.T) {
 	}
 }
 
+func TestClient_GetContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetContext(ctx, "/slow", nil)
+	if err == nil {
+		t.Fatal("Client.GetContext() expected error for expired context")
+	}
+
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Context error = %v, want %v", ctx.Err(), context.DeadlineExceeded)
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT request, got %v", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %v", r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("X-Custom") != "value" {
+			t.Errorf("Expected X-Custom header, got %v", r.Header.Get("X-Custom"))
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	headers := map[string]string{
+		"X-Custom": "value",
+	}
+
+	resp, err := client.Do(context.Background(), http.MethodPut, "/items/1", map[string]interface{}{"id": 1}, headers)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Client.Do() status = %v, want %v", resp.StatusCode, http.StatusAccepted)
+	}
+}
+
 func TestParseJSONResponse(t *testing.T) {
 	// Create test response
 	responseBody := `{"message": "success", "id": 123}`
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WithCoalescing makes concurrent identical GETs share a single upstream request.
+// Requests are identical when their URLs, credentials and the listed header values match.
+func WithCoalescing(headers ...string) Option {
+	return func(c *Client) {
+		c.use(stageCoalesce, CoalescingMiddleware(headers...))
+	}
+}
+
+// maxCoalescedBodyBytes bounds the response body buffered for sharing
+const maxCoalescedBodyBytes = 1 << 20
+
+// credentialHeaders are always part of the coalescing key so callers never share another caller's response
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+// CoalescingMiddleware returns middleware that collapses concurrent identical GETs.
+// Each caller receives its own copy of the response body. Streams and bodies over
+// 1 MB are not shared: the caller that started the request receives it as is and
+// the others send their own requests.
+func CoalescingMiddleware(headers ...string) Middleware {
+	return newCoalesceGroup().middleware(headers)
+}
+
+// coalesceGroup tracks in-flight calls by key
+type coalesceGroup struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// newCoalesceGroup creates an empty group
+func newCoalesceGroup() *coalesceGroup {
+	return &coalesceGroup{calls: make(map[string]*coalescedCall)}
+}
+
+// middleware collapses concurrent identical GETs into calls of the group
+func (g *coalesceGroup) middleware(headers []string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || isStreamingType(strings.Join(req.Header.Values("Accept"), ",")) {
+				return next.RoundTrip(req)
+			}
+			return g.do(coalesceKey(req, headers), req, next)
+		})
+	}
+}
+
+// coalescedCall is an in-flight request shared by several callers
+type coalescedCall struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+	// bypass reports that the response could not be shared
+	bypass bool
+	// callers counts the requests that joined the call, guarded by the group mutex
+	callers int
+}
+
+// do joins or starts the call for the key and waits for it on the caller's own context
+func (g *coalesceGroup) do(key string, req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	g.mu.Lock()
+	call, exists := g.calls[key]
+	if !exists {
+		call = &coalescedCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(key, call, req, next)
+	}
+	call.callers++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-req.Context().Done():
+		if !exists {
+			go call.discard()
+		}
+		return nil, req.Context().Err()
+	}
+
+	if call.bypass {
+		if !exists {
+			call.resp.Request = req
+			return call.resp, nil
+		}
+		return next.RoundTrip(req)
+	}
+	return call.copy(req)
+}
+
+// run sends the shared request; it keeps the deadline of the caller that started it
+// but not its cancellation, since other callers may still be waiting
+func (g *coalesceGroup) run(key string, call *coalescedCall, req *http.Request, next http.RoundTripper) {
+	ctx := context.WithoutCancel(req.Context())
+	var cancel context.CancelFunc
+	if deadline, ok := req.Context().Deadline(); ok {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	call.resp, call.err = next.RoundTrip(req.WithContext(ctx))
+	if call.err == nil {
+		call.body, call.bypass, call.err = readShared(call.resp)
+	}
+	if call.bypass {
+		// Only the leader reads an unshared body, so its cancellation applies again
+		stop := context.AfterFunc(req.Context(), cancel)
+		call.resp.Body = &cancelOnClose{ReadCloser: call.resp.Body, cancel: func() {
+			stop()
+			cancel()
+		}}
+	} else {
+		cancel()
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// readShared buffers a response body for sharing. Streams and large bodies are
+// reported as bypass and left readable in full for the leader.
+func readShared(resp *http.Response) ([]byte, bool, error) {
+	if isStreamingType(resp.Header.Get("Content-Type")) || resp.ContentLength > maxCoalescedBodyBytes {
+		return nil, true, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCoalescedBodyBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+	if len(body) > maxCoalescedBodyBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil, true, nil
+	}
+	resp.Body.Close()
+	return body, false, nil
+}
+
+// discard closes an unshared response once the leader that would have read it has gone
+func (c *coalescedCall) discard() {
+	<-c.done
+	if c.bypass {
+		c.resp.Body.Close()
+	}
+}
+
+// isStreamingType reports whether a media type list names an event or NDJSON stream
+func isStreamingType(value string) bool {
+	value = strings.ToLower(value)
+	return strings.Contains(value, "text/event-stream") ||
+		strings.Contains(value, "application/x-ndjson") ||
+		strings.Contains(value, "application/ndjson")
+}
+
+// copy returns an independent response for one caller
+func (c *coalescedCall) copy(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.ContentLength = int64(len(c.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+// coalesceKey identifies requests that may share a response
+func coalesceKey(req *http.Request, headers []string) string {
+	var key strings.Builder
+	key.WriteString(req.URL.String())
+	for _, names := range [][]string{credentialHeaders, headers} {
+		for _, name := range names {
+			key.WriteString("\n")
+			key.WriteString(http.CanonicalHeaderKey(name))
+			key.WriteString(":")
+			key.WriteString(strings.Join(req.Header.Values(name), ","))
+		}
+	}
+	return key.String()
+}
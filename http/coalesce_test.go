@@ -0,0 +1,305 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withCoalesceGroup coalesces requests in the given group so tests can observe it
+func withCoalesceGroup(group *coalesceGroup, headers ...string) Option {
+	return func(c *Client) {
+		c.use(stageCoalesce, group.middleware(headers))
+	}
+}
+
+// waitForCallers blocks until the group's in-flight calls have the given number of callers
+func waitForCallers(t *testing.T, group *coalesceGroup, callers int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		group.mu.Lock()
+		joined := 0
+		for _, call := range group.calls {
+			joined += call.callers
+		}
+		group.mu.Unlock()
+
+		if joined == callers {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Coalescing group has %d callers, want %d", joined, callers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClient_CoalescesIdenticalGets(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(`{"route":"MOW-LED"}`))
+	}))
+	defer server.Close()
+
+	group := newCoalesceGroup()
+	client := NewClient(server.URL, withCoalesceGroup(group, "Accept-Language"))
+
+	const callers = 10
+	bodies := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get("/search?route=MOW-LED", map[string]string{"Accept-Language": "ru"})
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	waitForCallers(t, group, callers)
+	close(release)
+	wg.Wait()
+
+	if hits != 1 {
+		t.Errorf("Upstream hits = %d, want 1", hits)
+	}
+	for i, body := range bodies {
+		if body != `{"route":"MOW-LED"}` {
+			t.Errorf("Caller %d body = %v", i, body)
+		}
+	}
+}
+
+func TestClient_CoalescingSeparatesHeaders(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer server.Close()
+
+	group := newCoalesceGroup()
+	client := NewClient(server.URL, withCoalesceGroup(group, "Accept-Language"))
+
+	var wg sync.WaitGroup
+	for _, lang := range []string{"ru", "en"} {
+		wg.Add(1)
+		go func(lang string) {
+			defer wg.Done()
+			resp, err := client.Get("/search", map[string]string{"Accept-Language": lang})
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			defer resp.Body.Close()
+
+			if body, _ := io.ReadAll(resp.Body); string(body) != lang {
+				t.Errorf("Client.Get() body = %v, want %v", string(body), lang)
+			}
+		}(lang)
+	}
+
+	waitForCallers(t, group, 2)
+	close(release)
+	wg.Wait()
+
+	if hits != 2 {
+		t.Errorf("Upstream hits = %d, want 2", hits)
+	}
+}
+
+func TestCoalescingMiddleware_SkipsNonGet(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCoalescing())
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post("/bookings", map[string]interface{}{"id": i}, nil)
+		if err != nil {
+			t.Fatalf("Client.Post() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if hits != 2 {
+		t.Errorf("Upstream hits = %d, want 2", hits)
+	}
+}
+
+func TestClient_CoalescingSurvivesLeaderCancel(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(`{"route":"MOW-LED"}`))
+	}))
+	defer server.Close()
+
+	group := newCoalesceGroup()
+	client := NewClient(server.URL, withCoalesceGroup(group))
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := client.GetContext(leaderCtx, "/search", nil)
+		leaderDone <- err
+	}()
+	waitForCallers(t, group, 1)
+
+	followerDone := make(chan string, 1)
+	go func() {
+		resp, err := client.Get("/search", nil)
+		if err != nil {
+			t.Errorf("Client.Get() follower error = %v", err)
+			followerDone <- ""
+			return
+		}
+		followerDone <- readBody(t, resp)
+	}()
+	waitForCallers(t, group, 2)
+
+	cancel()
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("Client.GetContext() leader error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+
+	if body := <-followerDone; body != `{"route":"MOW-LED"}` {
+		t.Errorf("Follower body = %v", body)
+	}
+	if hits != 1 {
+		t.Errorf("Upstream hits = %d, want 1", hits)
+	}
+}
+
+func TestClient_CoalescingBypassesStreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCoalescing())
+
+	for _, accept := range []string{"text/event-stream", "application/json"} {
+		resp, err := client.Get("/events", map[string]string{"Accept": accept})
+		if err != nil {
+			t.Fatalf("Client.Get() with Accept %s error = %v", accept, err)
+		}
+		line := make([]byte, len("data: 1\n"))
+		if _, err := io.ReadFull(resp.Body, line); err != nil || string(line) != "data: 1\n" {
+			t.Errorf("Client.Get() with Accept %s first line = %q, %v", accept, line, err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestClient_CoalescingLeaderKeepsLargeResponse(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	large := strings.Repeat("x", 2*maxCoalescedBodyBytes)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			<-release
+		}
+		w.Write([]byte(large))
+	}))
+	defer server.Close()
+
+	group := newCoalesceGroup()
+	client := NewClient(server.URL, withCoalesceGroup(group))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("/export", nil)
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			if body := readBody(t, resp); body != large {
+				t.Errorf("Client.Get() body length = %d, want %d", len(body), len(large))
+			}
+		}()
+		waitForCallers(t, group, i+1)
+	}
+	close(release)
+	wg.Wait()
+
+	if hits != 2 {
+		t.Errorf("Upstream hits = %d, want 2 for leader and follower", hits)
+	}
+}
+
+func TestClient_CoalescingSeparatesCredentials(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(r.Header.Get("Authorization") + r.Header.Get("Cookie")))
+	}))
+	defer server.Close()
+
+	group := newCoalesceGroup()
+	client := NewClient(server.URL, withCoalesceGroup(group))
+
+	var wg sync.WaitGroup
+	for _, headers := range []map[string]string{
+		{"Authorization": "Bearer alice"},
+		{"Authorization": "Bearer bob"},
+		{"Cookie": "session=carol"},
+	} {
+		wg.Add(1)
+		go func(headers map[string]string) {
+			defer wg.Done()
+			resp, err := client.Get("/profile", headers)
+			if err != nil {
+				t.Errorf("Client.Get() error = %v", err)
+				return
+			}
+			if body, want := readBody(t, resp), headers["Authorization"]+headers["Cookie"]; body != want {
+				t.Errorf("Client.Get() body = %v, want %v", body, want)
+			}
+		}(headers)
+	}
+
+	waitForCallers(t, group, 3)
+	close(release)
+	wg.Wait()
+
+	if hits != 3 {
+		t.Errorf("Upstream hits = %d, want 3", hits)
+	}
+}
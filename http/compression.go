@@ -0,0 +1,295 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressionConfig configures gzip compression of request and response bodies
+type CompressionConfig struct {
+	// MinSize is the smallest body in bytes that is compressed
+	MinSize int
+	// Level is the gzip compression level
+	Level int
+}
+
+// DefaultCompressionConfig returns default compression configuration
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		MinSize: 1024,
+		Level:   gzip.DefaultCompression,
+	}
+}
+
+// withDefaults replaces an invalid level with the default
+func (c CompressionConfig) withDefaults() CompressionConfig {
+	if c.Level < gzip.HuffmanOnly || c.Level > gzip.BestCompression {
+		c.Level = gzip.DefaultCompression
+	}
+	return c
+}
+
+// writerPool reuses gzip writers of one compression level
+type writerPool struct {
+	level int
+	pool  sync.Pool
+}
+
+// get returns a gzip writer reset to write to w
+func (p *writerPool) get(w io.Writer) *gzip.Writer {
+	if gz, ok := p.pool.Get().(*gzip.Writer); ok {
+		gz.Reset(w)
+		return gz
+	}
+	gz, _ := gzip.NewWriterLevel(w, p.level)
+	return gz
+}
+
+// put returns a closed writer to the pool
+func (p *writerPool) put(gz *gzip.Writer) {
+	p.pool.Put(gz)
+}
+
+// WithRequestCompression gzips request bodies of at least MinSize bytes
+func WithRequestCompression(config CompressionConfig) Option {
+	return func(c *Client) {
+		c.use(stageCompress, RequestCompressionMiddleware(config))
+	}
+}
+
+// RequestCompressionMiddleware gzips request bodies while they are sent.
+// Bodies of unknown length are always compressed.
+func RequestCompressionMiddleware(config CompressionConfig) Middleware {
+	config = config.withDefaults()
+	writers := &writerPool{level: config.Level}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+				return next.RoundTrip(req)
+			}
+			if req.ContentLength >= 0 && req.ContentLength < int64(config.MinSize) {
+				return next.RoundTrip(req)
+			}
+
+			compressed := req.Clone(req.Context())
+			compressed.Body = gzipBody(req.Body, writers)
+			compressed.ContentLength = -1
+			compressed.Header.Del("Content-Length")
+			compressed.Header.Set("Content-Encoding", "gzip")
+
+			if req.GetBody != nil {
+				compressed.GetBody = func() (io.ReadCloser, error) {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					return gzipBody(body, writers), nil
+				}
+			}
+
+			return next.RoundTrip(compressed)
+		})
+	}
+}
+
+// gzipBody compresses body on the fly through a pipe
+func gzipBody(body io.ReadCloser, writers *writerPool) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer body.Close()
+
+		gz := writers.get(pw)
+		_, err := io.Copy(gz, body)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+		writers.put(gz)
+		pw.CloseWithError(err)
+	}()
+
+	return pr
+}
+
+// CompressionMiddleware decompresses gzip request bodies and gzips responses
+// for clients that accept it. Responses smaller than MinSize are sent as is.
+func CompressionMiddleware(config CompressionConfig) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+	writers := &writerPool{level: config.Level}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+				body, err := gzip.NewReader(r.Body)
+				if err != nil {
+					WriteErrorResponse(w, http.StatusBadRequest, "Invalid gzip request body")
+					return
+				}
+				defer body.Close()
+
+				r.Body = body
+				r.ContentLength = -1
+				r.Header.Del("Content-Encoding")
+				r.Header.Del("Content-Length")
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, config: config, writers: writers}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip.
+// An explicit gzip entry takes precedence over the * wildcard.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if !strings.EqualFold(coding, "gzip") && coding != "*" {
+			continue
+		}
+
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding != "*" {
+			return q > 0
+		}
+		wildcard = q > 0
+	}
+	return wildcard
+}
+
+// gzipResponseWriter buffers up to MinSize bytes before deciding whether to compress
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	config  CompressionConfig
+	writers *writerPool
+	status  int
+	buf     []byte
+	gz      *gzip.Writer
+	decided bool
+}
+
+// WriteHeader delays the status until the encoding is decided
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers small bodies and compresses once MinSize is reached
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		if !w.compressible() {
+			w.start(false)
+		} else if len(w.buf)+len(b) < w.config.MinSize {
+			w.buf = append(w.buf, b...)
+			return len(b), nil
+		} else {
+			w.start(true)
+		}
+	}
+
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush starts compression of buffered data and flushes it to the client
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.start(w.compressible() && len(w.buf) > 0)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets protocol upgrades bypass compression
+func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	w.decided = true
+	return hijacker.Hijack()
+}
+
+// Close sends any buffered data and finishes the gzip stream
+func (w *gzipResponseWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 {
+			return nil
+		}
+		w.start(false)
+	}
+
+	if w.gz == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	w.writers.put(w.gz)
+	w.gz = nil
+	return err
+}
+
+// compressible reports whether the response may be gzipped
+func (w *gzipResponseWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	return w.status != http.StatusNoContent && w.status != http.StatusNotModified
+}
+
+// start writes the header and any buffered data, compressed or not
+func (w *gzipResponseWriter) start(compress bool) {
+	w.decided = true
+
+	if compress {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.status)
+		w.gz = w.writers.get(w.ResponseWriter)
+		if len(w.buf) > 0 {
+			w.gz.Write(w.buf)
+		}
+	} else {
+		w.ResponseWriter.WriteHeader(w.status)
+		if len(w.buf) > 0 {
+			w.ResponseWriter.Write(w.buf)
+		}
+	}
+	w.buf = nil
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestCompression(t *testing.T) {
+	payload := map[string]string{"route": strings.Repeat("MOW-LED ", 500)}
+	var compressedRequests int32
+
+	handler := CompressionMiddleware(DefaultCompressionConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := DecodeJSONRequest(r, &body); err != nil {
+			t.Errorf("DecodeJSONRequest() error = %v", err)
+		}
+		if !strings.HasPrefix(body["route"], "MOW-LED") {
+			t.Errorf("Server received route %.20q, want MOW-LED", body["route"])
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			atomic.AddInt32(&compressedRequests, 1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRequestCompression(DefaultCompressionConfig()))
+
+	resp, err := client.Post("/search", payload, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Post("/search", map[string]string{"route": "MOW-LED"}, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if compressedRequests != 1 {
+		t.Errorf("Compressed requests = %d, want 1", compressedRequests)
+	}
+}
+
+func TestRequestCompression_Retry(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(CompressionMiddleware(DefaultCompressionConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "MOW-LED") {
+			t.Errorf("Attempt %d received unexpected body of %d bytes", attempts, len(body))
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithRequestCompression(CompressionConfig{MinSize: 1, Level: gzip.BestSpeed}),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	resp, err := client.Post("/search", map[string]string{"route": "MOW-LED"}, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("Client.Post() status = %v after %d attempts, want 200 after 2", resp.StatusCode, attempts)
+	}
+}
+
+func TestCompressionMiddleware_Response(t *testing.T) {
+	large := map[string]string{"route": strings.Repeat("LED-MOW ", 500)}
+	handler := CompressionMiddleware(DefaultCompressionConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/small" {
+			WriteJSONResponse(w, http.StatusCreated, map[string]string{"route": "LED-MOW"})
+			return
+		}
+		WriteJSONResponse(w, http.StatusOK, large)
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantGzip       bool
+		wantStatus     int
+	}{
+		{"large gzip", "/large", "gzip, deflate", true, http.StatusOK},
+		{"large refused", "/large", "gzip;q=0", false, http.StatusOK},
+		{"large identity", "/large", "", false, http.StatusOK},
+		{"small", "/small", "gzip", false, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			gzipped := w.Header().Get("Content-Encoding") == "gzip"
+			if gzipped != tt.wantGzip {
+				t.Fatalf("Content-Encoding gzip = %v, want %v", gzipped, tt.wantGzip)
+			}
+
+			var body io.Reader = w.Body
+			if gzipped {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				body = gz
+			}
+			decoded, _ := io.ReadAll(body)
+			if !bytes.Contains(decoded, []byte("LED-MOW")) {
+				t.Errorf("Response body = %.40q, want route", decoded)
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_InvalidGzip(t *testing.T) {
+	handler := CompressionMiddleware(DefaultCompressionConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler called for invalid gzip body")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"br, GZIP;q=0.5":    true,
+		"gzip;q=0":          false,
+		"*":                 true,
+		"*;q=1, gzip;q=0":   false,
+		"gzip, *;q=0":       true,
+		"*;q=0":             false,
+		"deflate, identity": false,
+	}
+
+	for header, want := range tests {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// DefaultDeadlineHeader carries the remaining request budget in grpc-timeout format, e.g. "1500m"
+const DefaultDeadlineHeader = "X-Request-Timeout"
+
+// ErrBudgetExhausted is returned when too little of the context deadline remains to send a request
+var ErrBudgetExhausted = errors.New("request deadline budget exhausted")
+
+// DeadlineConfig configures deadline propagation on clients and servers
+type DeadlineConfig struct {
+	// Header carries the remaining budget
+	Header string
+	// MinBudget makes the client fail fast when less time remains
+	MinBudget time.Duration
+	// SafetyMargin is subtracted by the server to leave time for the response
+	SafetyMargin time.Duration
+	// TimeProvider measures the remaining budget
+	TimeProvider interfaces.TimeProvider
+}
+
+// DefaultDeadlineConfig returns default deadline propagation configuration
+func DefaultDeadlineConfig() DeadlineConfig {
+	return DeadlineConfig{
+		Header:       DefaultDeadlineHeader,
+		MinBudget:    5 * time.Millisecond,
+		SafetyMargin: 50 * time.Millisecond,
+		TimeProvider: systemClock,
+	}
+}
+
+// withDefaults fills zero fields from DefaultDeadlineConfig
+func (c DeadlineConfig) withDefaults() DeadlineConfig {
+	defaults := DefaultDeadlineConfig()
+	if c.Header == "" {
+		c.Header = defaults.Header
+	}
+	if c.TimeProvider == nil {
+		c.TimeProvider = defaults.TimeProvider
+	}
+	return c
+}
+
+// WithDeadlinePropagation sends the remaining context budget with every attempt
+func WithDeadlinePropagation(config DeadlineConfig) Option {
+	return func(c *Client) {
+		c.use(stageDeadline, DeadlinePropagationMiddleware(config))
+	}
+}
+
+// DeadlinePropagationMiddleware sets the budget header from the context deadline.
+// Requests with less than MinBudget remaining fail with ErrBudgetExhausted without being sent.
+func DeadlinePropagationMiddleware(config DeadlineConfig) Middleware {
+	config = config.withDefaults()
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			if !ok {
+				return next.RoundTrip(req)
+			}
+
+			remaining := deadline.Sub(config.TimeProvider.Now())
+			if remaining <= 0 || remaining < config.MinBudget {
+				closeRequestBody(req)
+				return nil, fmt.Errorf("%w: %v remaining: %w", ErrBudgetExhausted, remaining, context.DeadlineExceeded)
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set(config.Header, FormatTimeout(remaining))
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// DeadlineMiddleware derives the request context deadline from the budget header minus the safety margin.
+// Requests whose budget is already spent are rejected with 504 Gateway Timeout.
+func DeadlineMiddleware(config DeadlineConfig) func(http.Handler) http.Handler {
+	config = config.withDefaults()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			budget, err := ParseTimeout(r.Header.Get(config.Header))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			budget -= config.SafetyMargin
+			if budget <= 0 {
+				WriteErrorResponse(w, http.StatusGatewayTimeout, "Request deadline exceeded")
+				return
+			}
+
+			ctx, cancel := context.WithDeadline(r.Context(), config.TimeProvider.Now().Add(budget))
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// timeoutUnits lists grpc-timeout units from the finest to the coarsest
+var timeoutUnits = []struct {
+	suffix byte
+	unit   time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// maxTimeoutValue is the largest value allowed by the 8 digit grpc-timeout format
+const maxTimeoutValue = 99999999
+
+// FormatTimeout encodes d in grpc-timeout format using the finest unit that fits in 8 digits.
+// Negative durations are encoded as zero.
+func FormatTimeout(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	// Any time.Duration fits in 8 digits of hours, so the last unit always matches
+	for _, u := range timeoutUnits[:len(timeoutUnits)-1] {
+		if value := d / u.unit; value <= maxTimeoutValue {
+			return strconv.FormatInt(int64(value), 10) + string(u.suffix)
+		}
+	}
+	hours := timeoutUnits[len(timeoutUnits)-1]
+	return strconv.FormatInt(int64(d/hours.unit), 10) + string(hours.suffix)
+}
+
+// ParseTimeout decodes a grpc-timeout formatted duration, rejecting values that overflow time.Duration
+func ParseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+
+	value, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+
+	for _, u := range timeoutUnits {
+		if s[len(s)-1] == u.suffix {
+			if value > int64(math.MaxInt64/u.unit) {
+				return 0, fmt.Errorf("timeout %q overflows time.Duration", s)
+			}
+			return time.Duration(value) * u.unit, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timeout unit in %q", s)
+}
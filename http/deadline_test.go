@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+func TestClient_DeadlinePropagation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultDeadlineHeader) != "2000000u" {
+			t.Errorf("Expected budget 2000000u, got %v", r.Header.Get(DefaultDeadlineHeader))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	config := DefaultDeadlineConfig()
+	config.TimeProvider = providers.NewFixedTimeProvider(now)
+	client := NewClient(server.URL, WithDeadlinePropagation(config))
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(2*time.Second))
+	defer cancel()
+
+	resp, err := client.GetContext(ctx, "/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.GetContext() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_DeadlineBudgetExhausted(t *testing.T) {
+	var hits int32
+	server := newCountingServer(&hits, http.StatusOK)
+	defer server.Close()
+
+	now := time.Now()
+	config := DefaultDeadlineConfig()
+	config.MinBudget = 100 * time.Millisecond
+	config.TimeProvider = providers.NewFixedTimeProvider(now)
+	client := NewClient(server.URL,
+		WithDeadlinePropagation(config),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(50*time.Millisecond))
+	defer cancel()
+
+	_, err := client.GetContext(ctx, "/prices", nil)
+	if !errors.Is(err, ErrBudgetExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Client.GetContext() error = %v, want %v", err, ErrBudgetExhausted)
+	}
+	if hits != 0 {
+		t.Errorf("Server hits = %d, want 0", hits)
+	}
+}
+
+func TestDeadlineMiddleware(t *testing.T) {
+	var remaining time.Duration
+	handler := DeadlineMiddleware(DefaultDeadlineConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if deadline, ok := r.Context().Deadline(); ok {
+			remaining = time.Until(deadline)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/prices", nil)
+	req.Header.Set(DefaultDeadlineHeader, "1S")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if remaining <= 900*time.Millisecond || remaining > 950*time.Millisecond {
+		t.Errorf("Handler deadline in %v, want about 950ms", remaining)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/prices", nil)
+	req.Header.Set(DefaultDeadlineHeader, "20m")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Status for spent budget = %v, want %v", w.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func TestDeadlineMiddleware_NoHeader(t *testing.T) {
+	var called int32
+	handler := DeadlineMiddleware(DefaultDeadlineConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&called, 1)
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("Context has deadline without budget header")
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/prices", nil))
+
+	if called != 1 {
+		t.Error("Handler not called without budget header")
+	}
+}
+
+func TestFormatAndParseTimeout(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		encoded  string
+	}{
+		{500 * time.Nanosecond, "500n"},
+		{1500 * time.Millisecond, "1500000u"},
+		{2 * time.Minute, "120000m"},
+		{30 * time.Hour, "108000S"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTimeout(tt.duration); got != tt.encoded {
+			t.Errorf("FormatTimeout(%v) = %v, want %v", tt.duration, got, tt.encoded)
+		}
+		if got, err := ParseTimeout(tt.encoded); err != nil || got != tt.duration {
+			t.Errorf("ParseTimeout(%v) = %v, %v, want %v", tt.encoded, got, err, tt.duration)
+		}
+	}
+
+	for _, invalid := range []string{"", "5", "10x", "123456789S", "-1m", "99999999H"} {
+		if _, err := ParseTimeout(invalid); err == nil {
+			t.Errorf("ParseTimeout(%q) expected error", invalid)
+		}
+	}
+
+	encoded := FormatTimeout(time.Duration(math.MaxInt64))
+	if got, err := ParseTimeout(encoded); err != nil || got <= 0 || time.Duration(math.MaxInt64)-got >= time.Hour {
+		t.Errorf("ParseTimeout(FormatTimeout(max)) = %v, %v, want within an hour of the maximum", got, err)
+	}
+	if got := FormatTimeout(-time.Second); got != "0n" {
+		t.Errorf("FormatTimeout(-1s) = %v, want 0n", got)
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// DefaultMaxBodyBytes is the default limit for JSON request bodies decoded by DecodeJSONRequest
+const DefaultMaxBodyBytes int64 = 10 << 20
+
+// maxPreviewBytes bounds the body preview included in decode errors
+const maxPreviewBytes = 256
+
+// ErrBodyTooLarge is returned when a body exceeds its size limit
+var ErrBodyTooLarge = errors.New("body exceeds size limit")
+
+// DecodeOption configures JSON body decoding
+type DecodeOption func(*decodeOptions)
+
+// decodeOptions holds the settings applied by DecodeOption values
+type decodeOptions struct {
+	maxBytes         int64
+	checkContentType bool
+	disallowUnknown  bool
+}
+
+// MaxBodyBytes limits how much of the body is read, 0 or less means unlimited
+func MaxBodyBytes(n int64) DecodeOption {
+	return func(o *decodeOptions) {
+		o.maxBytes = n
+	}
+}
+
+// RequireJSONContentType rejects bodies whose Content-Type is not JSON
+func RequireJSONContentType() DecodeOption {
+	return func(o *decodeOptions) {
+		o.checkContentType = true
+	}
+}
+
+// DisallowUnknownFields rejects objects with fields missing from the destination struct
+func DisallowUnknownFields() DecodeOption {
+	return func(o *decodeOptions) {
+		o.disallowUnknown = true
+	}
+}
+
+// WithMaxResponseBytes limits every response body read through the client,
+// including the bodies decoded by GetJSON and the other JSON helpers
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// DecodeJSONRequest decodes a JSON request body into v, closing the body
+func DecodeJSONRequest(r *http.Request, v interface{}, opts ...DecodeOption) error {
+	defer r.Body.Close()
+
+	if err := decodeJSONBody(r.Body, r.Header.Get("Content-Type"), v, DefaultMaxBodyBytes, opts); err != nil {
+		return fmt.Errorf("failed to decode JSON request: %w", err)
+	}
+	return nil
+}
+
+// decodeJSONBody applies the options and unmarshals body into v, reading at most maxBytes unless overridden
+func decodeJSONBody(body io.Reader, contentType string, v interface{}, maxBytes int64, opts []DecodeOption) error {
+	options := decodeOptions{maxBytes: maxBytes}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.checkContentType {
+		if err := checkJSONContentType(contentType); err != nil {
+			return err
+		}
+	}
+
+	if options.maxBytes > 0 {
+		body = io.LimitReader(body, options.maxBytes+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if options.maxBytes > 0 && int64(len(data)) > options.maxBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, options.maxBytes)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if options.disallowUnknown {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %w (body: %q)", err, preview(data))
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to unmarshal JSON: unexpected data after value (body: %q)", preview(data))
+	}
+
+	return nil
+}
+
+// preview returns the start of the body, truncated on a rune boundary
+func preview(data []byte) string {
+	if len(data) <= maxPreviewBytes {
+		return string(data)
+	}
+
+	cut := maxPreviewBytes
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return string(data[:cut]) + "..."
+}
+
+// limitedBody fails reads once more than the allowed bytes have been read
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the body until the limit is exceeded
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = -1
+		return n, ErrBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newJSONResponse(contentType, body string) *http.Response {
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Content-Type", contentType)
+	recorder.WriteString(body)
+	return recorder.Result()
+}
+
+func TestParseJSONResponse_MaxBodyBytes(t *testing.T) {
+	resp := newJSONResponse("application/json", `{"route":"`+strings.Repeat("x", 100)+`"}`)
+
+	var flight testFlight
+	err := ParseJSONResponse(resp, &flight, MaxBodyBytes(50))
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("ParseJSONResponse() error = %v, want %v", err, ErrBodyTooLarge)
+	}
+}
+
+func TestParseJSONResponse_ContentType(t *testing.T) {
+	resp := newJSONResponse("text/html", `{"id":1}`)
+
+	var flight testFlight
+	if err := ParseJSONResponse(resp, &flight, RequireJSONContentType()); err == nil {
+		t.Error("ParseJSONResponse() expected error for text/html")
+	}
+}
+
+func TestParseJSONResponse_DisallowUnknownFields(t *testing.T) {
+	var flight testFlight
+	if err := ParseJSONResponse(newJSONResponse("application/json", `{"id":1,"price":4200}`), &flight); err != nil {
+		t.Fatalf("ParseJSONResponse() error = %v", err)
+	}
+
+	err := ParseJSONResponse(newJSONResponse("application/json", `{"id":1,"price":4200}`), &flight, DisallowUnknownFields())
+	if err == nil || !strings.Contains(err.Error(), "price") {
+		t.Errorf("ParseJSONResponse() error = %v, want unknown field price", err)
+	}
+}
+
+func TestParseJSONResponse_ErrorPreview(t *testing.T) {
+	body := "<html>" + strings.Repeat("gateway timeout ", 100) + "</html>"
+
+	var flight testFlight
+	err := ParseJSONResponse(newJSONResponse("application/json", body), &flight)
+	if err == nil {
+		t.Fatal("ParseJSONResponse() expected error for HTML body")
+	}
+	if !strings.Contains(err.Error(), "<html>gateway timeout") {
+		t.Errorf("ParseJSONResponse() error = %v, want body preview", err)
+	}
+	if strings.Contains(err.Error(), "</html>") {
+		t.Errorf("ParseJSONResponse() error preview not truncated: %v", err)
+	}
+}
+
+func TestClient_WithMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"route":"` + strings.Repeat("x", 1000) + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithMaxResponseBytes(100))
+
+	_, err := GetJSON[testFlight](context.Background(), client, "/flights/1", nil)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("GetJSON() error = %v, want %v", err, ErrBodyTooLarge)
+	}
+}
+
+func TestClient_LargeResponseWithinMaxResponseBytes(t *testing.T) {
+	route := strings.Repeat("x", int(DefaultMaxBodyBytes))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"route":"` + route + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithMaxResponseBytes(2*DefaultMaxBodyBytes))
+
+	flight, err := GetJSON[testFlight](context.Background(), client, "/flights/1", nil)
+	if err != nil {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+	if len(flight.Route) != len(route) {
+		t.Errorf("GetJSON() route length = %d, want %d", len(flight.Route), len(route))
+	}
+}
+
+func TestDecodeJSONRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/flights", strings.NewReader(`{"id":7,"route":"MOW-LED"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var flight testFlight
+	if err := DecodeJSONRequest(req, &flight, RequireJSONContentType(), DisallowUnknownFields()); err != nil {
+		t.Fatalf("DecodeJSONRequest() error = %v", err)
+	}
+	if flight.ID != 7 || flight.Route != "MOW-LED" {
+		t.Errorf("DecodeJSONRequest() = %+v, want ID 7 MOW-LED", flight)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/flights", strings.NewReader(`{"id":7} {"id":8}`))
+	if err := DecodeJSONRequest(req, &flight); err == nil {
+		t.Error("DecodeJSONRequest() expected error for trailing data")
+	}
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// BalancingStrategy selects which base URL serves an attempt
+type BalancingStrategy int
+
+const (
+	// RoundRobin rotates through healthy endpoints
+	RoundRobin BalancingStrategy = iota
+	// Random picks a healthy endpoint at random
+	Random
+	// PriorityFailover uses the first healthy endpoint in list order
+	PriorityFailover
+	// LeastOutstanding picks the healthy endpoint with the fewest in-flight requests
+	LeastOutstanding
+)
+
+// EndpointPoolConfig configures an EndpointPool
+type EndpointPoolConfig struct {
+	BaseURLs []string
+	Strategy BalancingStrategy
+	// FailureThreshold ejects an endpoint after this many consecutive failures, defaults to 3
+	FailureThreshold int
+	// Cooldown is how long an ejected endpoint is skipped, defaults to 30 seconds
+	Cooldown time.Duration
+	// TimeProvider supplies the current time, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+}
+
+// EndpointStatus describes the health of one base URL
+type EndpointStatus struct {
+	BaseURL      string
+	Healthy      bool
+	Outstanding  int
+	EjectedUntil time.Time
+}
+
+// EndpointPool balances requests across several base URLs with passive health tracking
+type EndpointPool struct {
+	config    EndpointPoolConfig
+	mu        sync.Mutex
+	upstreams []*upstream
+	next      int
+}
+
+// upstream is the state of one base URL
+type upstream struct {
+	baseURL      string
+	failures     int
+	outstanding  int
+	ejectedUntil time.Time
+}
+
+// NewEndpointPool creates a new EndpointPool
+func NewEndpointPool(config EndpointPoolConfig) (*EndpointPool, error) {
+	if len(config.BaseURLs) == 0 {
+		return nil, errors.New("endpoint pool requires at least one base URL")
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 3
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+
+	pool := &EndpointPool{config: config}
+	for _, baseURL := range config.BaseURLs {
+		if _, err := url.Parse(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+		}
+		pool.upstreams = append(pool.upstreams, &upstream{baseURL: baseURL})
+	}
+
+	return pool, nil
+}
+
+// NewClientWithBaseURLs creates a new HTTP client that balances across several base URLs
+func NewClientWithBaseURLs(baseURLs []string, strategy BalancingStrategy, opts ...Option) (*Client, error) {
+	pool, err := NewEndpointPool(EndpointPoolConfig{BaseURLs: baseURLs, Strategy: strategy})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient("", append([]Option{WithEndpointPool(pool)}, opts...)...), nil
+}
+
+// WithEndpointPool resolves each attempt against a base URL chosen from the pool
+func WithEndpointPool(pool *EndpointPool) Option {
+	return func(c *Client) {
+		c.endpoints = pool
+	}
+}
+
+// Status returns the current state of every endpoint in list order
+func (p *EndpointPool) Status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.config.TimeProvider.Now()
+	statuses := make([]EndpointStatus, len(p.upstreams))
+	for i, ep := range p.upstreams {
+		statuses[i] = EndpointStatus{
+			BaseURL:      ep.baseURL,
+			Healthy:      !now.Before(ep.ejectedUntil),
+			Outstanding:  ep.outstanding,
+			EjectedUntil: ep.ejectedUntil,
+		}
+	}
+	return statuses
+}
+
+// acquire picks an endpoint for an attempt, avoiding ones already tried when possible
+func (p *EndpointPool) acquire(tried map[*upstream]bool) *upstream {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.config.TimeProvider.Now()
+	candidates := p.candidates(now, tried)
+	if len(candidates) == 0 {
+		candidates = p.candidates(now, nil)
+	}
+
+	var chosen *upstream
+	if len(candidates) == 0 {
+		// Every endpoint is ejected, so use the one that recovers first
+		for _, ep := range p.upstreams {
+			if chosen == nil || ep.ejectedUntil.Before(chosen.ejectedUntil) {
+				chosen = ep
+			}
+		}
+	} else {
+		chosen = p.choose(candidates)
+	}
+
+	chosen.outstanding++
+	return chosen
+}
+
+// candidates returns healthy endpoints not in the excluded set; p.mu must be held
+func (p *EndpointPool) candidates(now time.Time, excluded map[*upstream]bool) []*upstream {
+	var healthy []*upstream
+	for _, ep := range p.upstreams {
+		if now.Before(ep.ejectedUntil) || excluded[ep] {
+			continue
+		}
+		if !ep.ejectedUntil.IsZero() {
+			// Cooldown is over: reinsert with a clean slate
+			ep.ejectedUntil = time.Time{}
+			ep.failures = 0
+		}
+		healthy = append(healthy, ep)
+	}
+	return healthy
+}
+
+// choose applies the balancing strategy; p.mu must be held
+func (p *EndpointPool) choose(candidates []*upstream) *upstream {
+	switch p.config.Strategy {
+	case Random:
+		return candidates[rand.Intn(len(candidates))]
+	case PriorityFailover:
+		return candidates[0]
+	case LeastOutstanding:
+		chosen := candidates[0]
+		for _, ep := range candidates[1:] {
+			if ep.outstanding < chosen.outstanding {
+				chosen = ep
+			}
+		}
+		return chosen
+	default:
+		chosen := candidates[p.next%len(candidates)]
+		p.next++
+		return chosen
+	}
+}
+
+// release records the attempt outcome and updates endpoint health
+func (p *EndpointPool) release(ep *upstream, resp *http.Response, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.outstanding--
+	if err == nil && resp.StatusCode < 500 {
+		ep.failures = 0
+		return
+	}
+
+	ep.failures++
+	if ep.failures >= p.config.FailureThreshold {
+		ep.ejectedUntil = p.config.TimeProvider.Now().Add(p.config.Cooldown)
+	}
+}
+
+// abandon returns an endpoint without recording an outcome
+func (p *EndpointPool) abandon(ep *upstream) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.outstanding--
+}
+
+// route points the request at an endpoint from the pool and returns a release function
+func (c *Client) route(req *http.Request, target string, tried map[*upstream]bool) (func(*http.Response, error), error) {
+	ep := c.endpoints.acquire(tried)
+	tried[ep] = true
+
+	routed, err := url.Parse(resolveURL(ep.baseURL, target))
+	if err != nil {
+		c.endpoints.release(ep, nil, err)
+		return nil, fmt.Errorf("failed to resolve %q against %q: %w", target, ep.baseURL, err)
+	}
+	req.URL = routed
+	req.Host = ""
+
+	return func(resp *http.Response, err error) {
+		if err != nil && (req.Context().Err() != nil || isLocalFailure(err)) {
+			// Caller cancellation and local rejections say nothing about upstream health
+			c.endpoints.abandon(ep)
+			return
+		}
+		c.endpoints.release(ep, resp, err)
+	}, nil
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingServer(hits *int32, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestClient_RoundRobinBaseURLs(t *testing.T) {
+	var hitsA, hitsB int32
+	serverA := newCountingServer(&hitsA, http.StatusOK)
+	defer serverA.Close()
+	serverB := newCountingServer(&hitsB, http.StatusOK)
+	defer serverB.Close()
+
+	client, err := NewClientWithBaseURLs([]string{serverA.URL, serverB.URL}, RoundRobin)
+	if err != nil {
+		t.Fatalf("NewClientWithBaseURLs() error = %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get("/test", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if hitsA != 2 || hitsB != 2 {
+		t.Errorf("Upstream hits = %d/%d, want 2/2", hitsA, hitsB)
+	}
+}
+
+func TestClient_FailoverOnRetry(t *testing.T) {
+	var hitsPrimary, hitsSecondary int32
+	primary := newCountingServer(&hitsPrimary, http.StatusServiceUnavailable)
+	defer primary.Close()
+	secondary := newCountingServer(&hitsSecondary, http.StatusOK)
+	defer secondary.Close()
+
+	pool, err := NewEndpointPool(EndpointPoolConfig{
+		BaseURLs: []string{primary.URL, secondary.URL},
+		Strategy: PriorityFailover,
+	})
+	if err != nil {
+		t.Fatalf("NewEndpointPool() error = %v", err)
+	}
+	client := NewClient("", WithEndpointPool(pool), WithRetryPolicy(DefaultRetryPolicy()), WithClock(newFakeClock()))
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	if hitsPrimary != 1 || hitsSecondary != 1 {
+		t.Errorf("Upstream hits = %d/%d, want 1/1", hitsPrimary, hitsSecondary)
+	}
+}
+
+func TestEndpointPool_EjectsAndReinserts(t *testing.T) {
+	var hitsPrimary, hitsSecondary int32
+	primary := newCountingServer(&hitsPrimary, http.StatusBadGateway)
+	defer primary.Close()
+	secondary := newCountingServer(&hitsSecondary, http.StatusOK)
+	defer secondary.Close()
+
+	clock := newFakeClock()
+	pool, err := NewEndpointPool(EndpointPoolConfig{
+		BaseURLs:         []string{primary.URL, secondary.URL},
+		Strategy:         PriorityFailover,
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		TimeProvider:     clock,
+	})
+	if err != nil {
+		t.Fatalf("NewEndpointPool() error = %v", err)
+	}
+	client := NewClient("", WithEndpointPool(pool))
+
+	get := func() {
+		resp, err := client.Get("/test", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	for i := 0; i < 4; i++ {
+		get()
+	}
+	if hitsPrimary != 2 || hitsSecondary != 2 {
+		t.Errorf("Upstream hits after ejection = %d/%d, want 2/2", hitsPrimary, hitsSecondary)
+	}
+	if status := pool.Status(); status[0].Healthy {
+		t.Error("EndpointPool.Status() primary healthy, want ejected")
+	}
+
+	clock.SetTime(clock.Now().Add(time.Minute))
+	get()
+	if hitsPrimary != 3 {
+		t.Errorf("Primary hits after cooldown = %d, want 3", hitsPrimary)
+	}
+}
+
+func TestEndpointPool_LeastOutstanding(t *testing.T) {
+	pool, err := NewEndpointPool(EndpointPoolConfig{
+		BaseURLs: []string{"https://a.example.com", "https://b.example.com"},
+		Strategy: LeastOutstanding,
+	})
+	if err != nil {
+		t.Fatalf("NewEndpointPool() error = %v", err)
+	}
+
+	first := pool.acquire(nil)
+	second := pool.acquire(nil)
+	if first == second {
+		t.Errorf("EndpointPool.acquire() picked %v twice, want least outstanding", first.baseURL)
+	}
+
+	pool.release(first, &http.Response{StatusCode: http.StatusOK}, nil)
+	if third := pool.acquire(nil); third != first {
+		t.Errorf("EndpointPool.acquire() = %v, want %v", third.baseURL, first.baseURL)
+	}
+}
+
+func TestNewEndpointPool_RequiresBaseURL(t *testing.T) {
+	if _, err := NewEndpointPool(EndpointPoolConfig{}); err == nil {
+		t.Error("NewEndpointPool() expected error for empty base URLs")
+	}
+}
+
+func TestEndpointPool_IgnoresCallerCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	pool, err := NewEndpointPool(EndpointPoolConfig{
+		BaseURLs:         []string{server.URL},
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+		TimeProvider:     newFakeClock(),
+	})
+	if err != nil {
+		t.Fatalf("NewEndpointPool() error = %v", err)
+	}
+	client := NewClient("", WithEndpointPool(pool))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetContext(ctx, "/test", nil); err == nil {
+		t.Fatal("Client.GetContext() expected error for canceled request")
+	}
+
+	if status := pool.Status(); !status[0].Healthy {
+		t.Error("EndpointPool.Status() endpoint ejected after caller cancellation, want healthy")
+	}
+}
+
+func TestEndpointPool_IgnoresLocalRejections(t *testing.T) {
+	var hits int32
+	server := newCountingServer(&hits, http.StatusOK)
+	defer server.Close()
+
+	pool, err := NewEndpointPool(EndpointPoolConfig{
+		BaseURLs:         []string{server.URL},
+		FailureThreshold: 3,
+		Cooldown:         time.Minute,
+		TimeProvider:     newFakeClock(),
+	})
+	if err != nil {
+		t.Fatalf("NewEndpointPool() error = %v", err)
+	}
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 0.001, Burst: 1},
+		Mode:  RateLimitFailFast,
+		Clock: newFakeClock(),
+	})
+	client := NewClient("", WithEndpointPool(pool), WithRateLimiter(limiter))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("/test", nil); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("Client.Get() error = %v, want %v", err, ErrRateLimited)
+		}
+	}
+
+	if status := pool.Status(); !status[0].Healthy {
+		t.Error("EndpointPool.Status() endpoint ejected after local rejections, want healthy")
+	}
+	if hits != 1 {
+		t.Errorf("Server hits = %d, want 1", hits)
+	}
+}
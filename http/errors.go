@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBodyBytes bounds how much of an error response body is kept
+const maxErrorBodyBytes = 4096
+
+// HTTPError describes a response with a non-2xx status code
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	// Body holds at most maxErrorBodyBytes of the response body
+	Body []byte
+	// Message is taken from the standard {"error":{"message"}} payload if present
+	Message string
+}
+
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: status %d", e.Method, e.URL, e.StatusCode)
+}
+
+// WithHTTPErrors makes non-2xx responses return an *HTTPError instead of a response
+func WithHTTPErrors() Option {
+	return func(c *Client) {
+		c.httpErrors = true
+	}
+}
+
+// NewHTTPError builds an *HTTPError from the response and closes its body
+func NewHTTPError(resp *http.Response) *HTTPError {
+	defer resp.Body.Close()
+
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if resp.Request != nil {
+		httpErr.Method = resp.Request.Method
+		httpErr.URL = resp.Request.URL.String()
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	httpErr.Body = body
+	httpErr.Message = errorMessage(body)
+
+	return httpErr
+}
+
+// errorMessage extracts the message written by WriteErrorResponse
+func errorMessage(body []byte) string {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.Error.Message
+}
+
+// isSuccess reports whether the status code is in the 2xx range
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// StatusCode returns the status code carried by an *HTTPError, or 0
+func StatusCode(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether the error is a 404 response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsRateLimited reports whether the error is a 429 response
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+// IsClientError reports whether the error is a 4xx response
+func IsClientError(err error) bool {
+	code := StatusCode(err)
+	return code >= 400 && code < 500
+}
+
+// IsServerError reports whether the error is a 5xx response
+func IsServerError(err error) bool {
+	code := StatusCode(err)
+	return code >= 500 && code < 600
+}
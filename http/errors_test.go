@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_HTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, http.StatusNotFound, "Route not found")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHTTPErrors())
+
+	resp, err := client.Get("/routes/MOW-LED", nil)
+	if resp != nil {
+		t.Errorf("Client.Get() response = %v, want nil", resp)
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Client.Get() error = %v, want *HTTPError", err)
+	}
+
+	if httpErr.Method != http.MethodGet {
+		t.Errorf("HTTPError.Method = %v, want %v", httpErr.Method, http.MethodGet)
+	}
+	if !strings.HasSuffix(httpErr.URL, "/routes/MOW-LED") {
+		t.Errorf("HTTPError.URL = %v, want suffix /routes/MOW-LED", httpErr.URL)
+	}
+	if httpErr.Message != "Route not found" {
+		t.Errorf("HTTPError.Message = %v, want 'Route not found'", httpErr.Message)
+	}
+	if httpErr.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("HTTPError.Header Content-Type = %v, want application/json", httpErr.Header.Get("Content-Type"))
+	}
+	if !IsNotFound(err) {
+		t.Error("IsNotFound() = false, want true")
+	}
+}
+
+func TestClient_HTTPErrorsDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestNewHTTPError_TruncatesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(strings.Repeat("x", maxErrorBodyBytes*2)))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get test response: %v", err)
+	}
+
+	httpErr := NewHTTPError(resp)
+	if len(httpErr.Body) != maxErrorBodyBytes {
+		t.Errorf("HTTPError.Body length = %d, want %d", len(httpErr.Body), maxErrorBodyBytes)
+	}
+	if httpErr.Message != "" {
+		t.Errorf("HTTPError.Message = %v, want empty", httpErr.Message)
+	}
+}
+
+func TestHTTPError_Helpers(t *testing.T) {
+	tests := []struct {
+		status      int
+		notFound    bool
+		rateLimited bool
+		clientError bool
+		serverError bool
+	}{
+		{http.StatusNotFound, true, false, true, false},
+		{http.StatusTooManyRequests, false, true, true, false},
+		{http.StatusServiceUnavailable, false, false, false, true},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: tt.status})
+
+		if IsNotFound(err) != tt.notFound {
+			t.Errorf("IsNotFound(%d) = %v, want %v", tt.status, !tt.notFound, tt.notFound)
+		}
+		if IsRateLimited(err) != tt.rateLimited {
+			t.Errorf("IsRateLimited(%d) = %v, want %v", tt.status, !tt.rateLimited, tt.rateLimited)
+		}
+		if IsClientError(err) != tt.clientError {
+			t.Errorf("IsClientError(%d) = %v, want %v", tt.status, !tt.clientError, tt.clientError)
+		}
+		if IsServerError(err) != tt.serverError {
+			t.Errorf("IsServerError(%d) = %v, want %v", tt.status, !tt.serverError, tt.serverError)
+		}
+	}
+
+	if IsServerError(errors.New("plain error")) {
+		t.Error("IsServerError() on plain error = true, want false")
+	}
+}
@@ -0,0 +1,314 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeConfig configures hedged requests
+type HedgeConfig struct {
+	// Delay is how long to wait before sending the next copy
+	Delay time.Duration
+	// Percentile, if set, replaces Delay with this latency percentile (0-1) once enough samples exist
+	Percentile float64
+	// MinSamples is the number of latency samples needed before Percentile applies, defaults to 20
+	MinSamples int
+	// MaxHedges is the number of extra copies per request, defaults to 1
+	MaxHedges int
+	// MaxExtraLoad caps hedges as a fraction of requests, 0 means no cap
+	MaxExtraLoad float64
+	// Endpoints limits hedging to paths matching these path.Match patterns; empty means all
+	Endpoints []string
+	// Clock measures latencies and waits between copies, defaults to the system clock
+	Clock Clock
+}
+
+// HedgeStats reports how hedging behaved
+type HedgeStats struct {
+	// Requests is the number of hedge-eligible requests
+	Requests int64
+	// Hedges is the number of extra copies sent
+	Hedges int64
+	// HedgeWins is the number of requests answered by a hedge rather than the original
+	HedgeWins int64
+}
+
+// Hedger sends extra copies of slow idempotent requests and keeps the first success
+type Hedger struct {
+	config    HedgeConfig
+	mu        sync.Mutex
+	stats     HedgeStats
+	latencies []time.Duration
+	next      int
+}
+
+// hedgeResult is the outcome of one copy
+type hedgeResult struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// maxLatencySamples bounds the latency window used for percentiles
+const maxLatencySamples = 1000
+
+// NewHedger creates a new Hedger
+func NewHedger(config HedgeConfig) *Hedger {
+	if config.MinSamples <= 0 {
+		config.MinSamples = 20
+	}
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	if config.Clock == nil {
+		config.Clock = systemClock
+	}
+	return &Hedger{config: config}
+}
+
+// WithHedging hedges eligible requests with the hedger
+func WithHedging(hedger *Hedger) Option {
+	return func(c *Client) {
+		c.use(stageHedge, hedger.Middleware())
+	}
+}
+
+// Stats returns a snapshot of hedging statistics
+func (h *Hedger) Stats() HedgeStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.stats
+}
+
+// Middleware returns middleware that hedges eligible requests
+func (h *Hedger) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !h.eligible(req) {
+				return next.RoundTrip(req)
+			}
+			return h.roundTrip(req, next)
+		})
+	}
+}
+
+// roundTrip races copies of the request and returns the first success
+func (h *Hedger) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	h.mu.Lock()
+	h.stats.Requests++
+	h.mu.Unlock()
+
+	results := make(chan hedgeResult, h.config.MaxHedges+1)
+	cancels := make([]context.CancelFunc, 0, h.config.MaxHedges+1)
+	launch := func(index int) error {
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			attempt.Body = body
+		}
+
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := next.RoundTrip(attempt)
+			results <- hedgeResult{index: index, resp: resp, err: err, cancel: cancel}
+		}()
+		return nil
+	}
+
+	if req.GetBody != nil {
+		// Every copy sends a body from GetBody, so the original is only closed
+		defer closeRequestBody(req)
+	}
+
+	start := h.config.Clock.Now()
+	if err := launch(0); err != nil {
+		return nil, err
+	}
+	launched, inflight := 1, 1
+
+	timer := h.startTimer(req.Context(), h.delay())
+	defer func() { timer.stop() }()
+
+	var last hedgeResult
+	for {
+		select {
+		case <-timer.fired:
+			if launched <= h.config.MaxHedges && h.allowHedge() && launch(launched) == nil {
+				launched++
+				inflight++
+				if launched <= h.config.MaxHedges {
+					timer.stop()
+					timer = h.startTimer(req.Context(), h.delay())
+				}
+			}
+
+		case result := <-results:
+			inflight--
+			if result.err == nil && result.resp.StatusCode < 500 {
+				h.recordWin(result.index, h.config.Clock.Now().Sub(start))
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				go discardHedges(results, inflight)
+				last.discard()
+				return result.response()
+			}
+
+			last.discard()
+			last = result
+
+			if inflight == 0 {
+				// Failures are left to the retry policy; hedges only race slow copies
+				return last.response()
+			}
+		}
+	}
+}
+
+// hedgeTimer signals once the hedger clock has waited out a delay
+type hedgeTimer struct {
+	fired <-chan struct{}
+	stop  context.CancelFunc
+}
+
+// startTimer waits for d on the hedger clock until ctx is done or the timer is stopped
+func (h *Hedger) startTimer(ctx context.Context, d time.Duration) hedgeTimer {
+	ctx, cancel := context.WithCancel(ctx)
+	fired := make(chan struct{}, 1)
+	go func() {
+		if h.config.Clock.Sleep(ctx, d) == nil {
+			fired <- struct{}{}
+		}
+	}()
+	return hedgeTimer{fired: fired, stop: cancel}
+}
+
+// response hands the result to the caller, tying the copy's context to the body
+func (r hedgeResult) response() (*http.Response, error) {
+	if r.resp == nil {
+		r.cancel()
+		return nil, r.err
+	}
+
+	r.resp.Body = &cancelOnClose{ReadCloser: r.resp.Body, cancel: r.cancel}
+	return r.resp, nil
+}
+
+// discard releases a result that will not be returned
+func (r hedgeResult) discard() {
+	if r.cancel == nil {
+		return
+	}
+	if r.resp != nil {
+		drainBody(r.resp)
+	}
+	r.cancel()
+}
+
+// eligible reports whether the request is idempotent and matches the configured endpoints
+func (h *Hedger) eligible(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	case http.MethodPut, http.MethodDelete:
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if len(h.config.Endpoints) == 0 {
+		return true
+	}
+	for _, pattern := range h.config.Endpoints {
+		if matched, _ := path.Match(pattern, req.URL.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// allowHedge reserves a hedge if the extra load cap permits it
+func (h *Hedger) allowHedge() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.config.MaxExtraLoad > 0 && float64(h.stats.Hedges+1) > h.config.MaxExtraLoad*float64(h.stats.Requests) {
+		return false
+	}
+	h.stats.Hedges++
+	return true
+}
+
+// recordWin records the winning copy and its latency
+func (h *Hedger) recordWin(index int, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if index > 0 {
+		h.stats.HedgeWins++
+	}
+
+	if len(h.latencies) < maxLatencySamples {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % maxLatencySamples
+}
+
+// delay returns the wait before the next hedge
+func (h *Hedger) delay() time.Duration {
+	if h.config.Percentile <= 0 {
+		return h.config.Delay
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < h.config.MinSamples {
+		return h.config.Delay
+	}
+
+	sorted := make([]time.Duration, len(h.latencies))
+	copy(sorted, h.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(h.config.Percentile * float64(len(sorted)-1))
+	return sorted[index]
+}
+
+// discardHedges closes responses from copies that lost the race
+func discardHedges(results <-chan hedgeResult, remaining int) {
+	for i := 0; i < remaining; i++ {
+		result := <-results
+		result.discard()
+	}
+}
+
+// cancelOnClose releases a request context once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSlowFirstServer(calls *int32, slow time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) == 1 {
+			select {
+			case <-time.After(slow):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+}
+
+func TestHedger_HedgeWins(t *testing.T) {
+	var calls int32
+	server := newSlowFirstServer(&calls, 2*time.Second)
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: 20 * time.Millisecond})
+	client := NewClient(server.URL, WithHedging(hedger))
+
+	start := time.Now()
+	resp, err := client.Get("/prices/MOW-LED", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	if body := readBody(t, resp); body != "/prices/MOW-LED" {
+		t.Errorf("Client.Get() body = %v, want /prices/MOW-LED", body)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.Get() took %v, want hedge to answer quickly", elapsed)
+	}
+
+	stats := hedger.Stats()
+	if stats.Requests != 1 || stats.Hedges != 1 || stats.HedgeWins != 1 {
+		t.Errorf("Hedger.Stats() = %+v, want 1 request, 1 hedge, 1 win", stats)
+	}
+}
+
+func TestHedger_FastPrimaryNoHedge(t *testing.T) {
+	var calls int32
+	server := newSlowFirstServer(&calls, 0)
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: time.Second})
+	client := NewClient(server.URL, WithHedging(hedger))
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	readBody(t, resp)
+
+	if stats := hedger.Stats(); stats.Hedges != 0 || stats.HedgeWins != 0 {
+		t.Errorf("Hedger.Stats() = %+v, want no hedges", stats)
+	}
+	if calls != 1 {
+		t.Errorf("Upstream calls = %d, want 1", calls)
+	}
+}
+
+func TestHedger_MaxExtraLoad(t *testing.T) {
+	var calls int32
+	server := newSlowFirstServer(&calls, 100*time.Millisecond)
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: 10 * time.Millisecond, MaxExtraLoad: 0.5})
+	client := NewClient(server.URL, WithHedging(hedger))
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	readBody(t, resp)
+
+	if stats := hedger.Stats(); stats.Hedges != 0 {
+		t.Errorf("Hedger.Stats() hedges = %d, want 0 under load cap", stats.Hedges)
+	}
+}
+
+func TestHedger_Eligibility(t *testing.T) {
+	hedger := NewHedger(HedgeConfig{Endpoints: []string{"/prices/*"}})
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/prices/MOW", true},
+		{http.MethodGet, "/bookings/1", false},
+		{http.MethodPost, "/prices/MOW", false},
+		{http.MethodHead, "/prices/MOW", true},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "https://api.example.com"+tt.path, nil)
+		if got := hedger.eligible(req); got != tt.want {
+			t.Errorf("Hedger.eligible(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHedger_PercentileDelay(t *testing.T) {
+	hedger := NewHedger(HedgeConfig{Delay: time.Second, Percentile: 0.5, MinSamples: 3})
+
+	if delay := hedger.delay(); delay != time.Second {
+		t.Errorf("Hedger.delay() without samples = %v, want %v", delay, time.Second)
+	}
+
+	for _, latency := range []time.Duration{10, 30, 20} {
+		hedger.recordWin(0, latency*time.Millisecond)
+	}
+	if delay := hedger.delay(); delay != 20*time.Millisecond {
+		t.Errorf("Hedger.delay() = %v, want %v", delay, 20*time.Millisecond)
+	}
+}
+
+// recordingClock reports a fixed time and returns from Sleep at once, recording the delays
+type recordingClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *recordingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *recordingClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	return ctx.Err()
+}
+
+func TestHedger_UsesClock(t *testing.T) {
+	var calls int32
+	server := newSlowFirstServer(&calls, time.Second)
+	defer server.Close()
+
+	clock := &recordingClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	hedger := NewHedger(HedgeConfig{Delay: time.Hour, Percentile: 0.5, MinSamples: 1, Clock: clock})
+	client := NewClient(server.URL, WithHedging(hedger))
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if stats := hedger.Stats(); stats.Hedges != 1 {
+		t.Errorf("Hedger.Stats().Hedges = %d, want 1", stats.Hedges)
+	}
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if len(clock.sleeps) == 0 || clock.sleeps[0] != time.Hour {
+		t.Errorf("Clock sleeps = %v, want a first wait of %v", clock.sleeps, time.Hour)
+	}
+	if delay := hedger.delay(); delay != 0 {
+		t.Errorf("Hedger.delay() = %v, want 0 for latency measured on the fixed clock", delay)
+	}
+}
+
+func TestHedger_FastFailureNotHedged(t *testing.T) {
+	var calls int32
+	server := newCountingServer(&calls, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	hedger := NewHedger(HedgeConfig{Delay: time.Second, MaxHedges: 2})
+	client := NewClient(server.URL, WithHedging(hedger))
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("Client.Get() status = %d after %d calls, want %d after 1", resp.StatusCode, calls, http.StatusServiceUnavailable)
+	}
+	if stats := hedger.Stats(); stats.Hedges != 0 {
+		t.Errorf("Hedger.Stats() hedges = %d, want 0 for a fast failure", stats.Hedges)
+	}
+}
+
+// closeTracker records whether the body was closed
+type closeTracker struct {
+	io.Reader
+	closed int32
+}
+
+func (c *closeTracker) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return nil
+}
+
+func TestHedger_ClosesOriginalBody(t *testing.T) {
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	hedged := NewHedger(HedgeConfig{Delay: time.Second}).Middleware()(transport)
+
+	body := &closeTracker{Reader: strings.NewReader(`{"seat":"1A"}`)}
+	req := httptest.NewRequest(http.MethodPut, "/seats", nil)
+	req.Body = body
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"seat":"1A"}`)), nil
+	}
+
+	resp, err := hedged.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Hedger.RoundTrip() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if atomic.LoadInt32(&body.closed) != 1 {
+		t.Error("Hedger.RoundTrip() left the original request body open")
+	}
+}
@@ -0,0 +1,349 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+// DefaultIdempotencyKeyHeader is the header used to carry idempotency keys
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks responses replayed from the idempotency store
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// ErrIdempotencyInProgress is returned when a request with the same key is still being processed
+var ErrIdempotencyInProgress = errors.New("request with idempotency key in progress")
+
+// idempotencyKeyKey is the context key for the idempotency key
+type idempotencyKeyKey struct{}
+
+// ContextWithIdempotencyKey returns a context carrying the idempotency key
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in the context, if any
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// WithIdempotencyKeys generates idempotency keys for POST and PUT calls whose context does not carry one
+func WithIdempotencyKeys(generator interfaces.IDGenerator) Option {
+	return func(c *Client) {
+		c.idempotencyKeyGenerator = generator
+	}
+}
+
+// WithIdempotencyKeyHeader sets the header used to send idempotency keys
+func WithIdempotencyKeyHeader(name string) Option {
+	return func(c *Client) {
+		c.idempotencyKeyHeader = name
+	}
+}
+
+// setIdempotencyKey attaches the idempotency key once per logical call so retries share it
+func (c *Client) setIdempotencyKey(req *http.Request) {
+	if req.Header.Get(c.idempotencyKeyHeader) != "" {
+		return
+	}
+
+	key := IdempotencyKeyFromContext(req.Context())
+	if key == "" && c.idempotencyKeyGenerator != nil && isIdempotencyMethod(req.Method) {
+		key = c.idempotencyKeyGenerator.Generate()
+	}
+	if key != "" {
+		req.Header.Set(c.idempotencyKeyHeader, key)
+	}
+}
+
+// isIdempotencyMethod reports whether keys are generated for the method
+func isIdempotencyMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut
+}
+
+// IdempotentResponse is a response stored for replay
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// RequestHash is the hex SHA-256 of the request body that produced the response
+	RequestHash string
+}
+
+// IdempotencyStore tracks idempotency keys; implementations must be safe for concurrent use
+type IdempotencyStore interface {
+	// Begin reserves the key. It returns the stored response if the key has completed,
+	// or ErrIdempotencyInProgress if another request holds the key.
+	Begin(key string) (*IdempotentResponse, error)
+	// Complete stores the response for the reserved key
+	Complete(key string, resp *IdempotentResponse)
+	// Release frees the reserved key without storing a response
+	Release(key string)
+}
+
+// idempotencyEntry is a reserved or completed key
+type idempotencyEntry struct {
+	response *IdempotentResponse
+	expires  time.Time
+}
+
+// InMemoryIdempotencyStore keeps idempotency keys in memory until their TTL expires
+type InMemoryIdempotencyStore struct {
+	ttl          time.Duration
+	timeProvider interfaces.TimeProvider
+	mu           sync.Mutex
+	entries      map[string]*idempotencyEntry
+	nextSweep    time.Time
+}
+
+// NewInMemoryIdempotencyStore creates an in-memory store; a nil time provider uses the system clock
+func NewInMemoryIdempotencyStore(ttl time.Duration, timeProvider interfaces.TimeProvider) *InMemoryIdempotencyStore {
+	if timeProvider == nil {
+		timeProvider = systemClock
+	}
+
+	return &InMemoryIdempotencyStore{
+		ttl:          ttl,
+		timeProvider: timeProvider,
+		entries:      make(map[string]*idempotencyEntry),
+	}
+}
+
+// Begin reserves the key or returns its stored response
+func (s *InMemoryIdempotencyStore) Begin(key string) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeProvider.Now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		if entry.response == nil {
+			return nil, ErrIdempotencyInProgress
+		}
+		return entry.response, nil
+	}
+
+	s.entries[key] = &idempotencyEntry{expires: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Complete stores the response for the key
+func (s *InMemoryIdempotencyStore) Complete(key string, resp *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &idempotencyEntry{
+		response: resp,
+		expires:  s.timeProvider.Now().Add(s.ttl),
+	}
+}
+
+// Release removes the key
+func (s *InMemoryIdempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+// Len returns the number of stored keys
+func (s *InMemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// sweep drops expired entries at most once per TTL
+func (s *InMemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(s.ttl)
+}
+
+// IdempotencyConfig configures IdempotencyMiddleware
+type IdempotencyConfig struct {
+	Store  IdempotencyStore
+	Header string
+	// Scope partitions keys, e.g. by authenticated subject, so clients cannot replay each other's responses
+	Scope func(r *http.Request) string
+	// MaxBodyBytes limits the request body hashed to detect key reuse, defaults to DefaultMaxBodyBytes
+	MaxBodyBytes int64
+}
+
+// DefaultIdempotencyConfig returns default idempotency configuration with a 24 hour in-memory store
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Store:        NewInMemoryIdempotencyStore(24*time.Hour, nil),
+		Header:       DefaultIdempotencyKeyHeader,
+		MaxBodyBytes: DefaultMaxBodyBytes,
+	}
+}
+
+// IdempotencyMiddleware replays the stored response for repeated idempotency keys
+// and rejects requests whose key is still being processed with 409 Conflict.
+// Keys are scoped by Scope, method and path; a key reused with a different body is
+// rejected with 422 Unprocessable Entity. 5xx responses are not stored so clients can retry.
+func IdempotencyMiddleware(config IdempotencyConfig) func(http.Handler) http.Handler {
+	defaults := DefaultIdempotencyConfig()
+	if config.Store == nil {
+		config.Store = defaults.Store
+	}
+	if config.Header == "" {
+		config.Header = defaults.Header
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(config.Header)
+			if key == "" || !isIdempotencyMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key = r.Method + " " + r.URL.Path + " " + key
+			if config.Scope != nil {
+				key = config.Scope(r) + " " + key
+			}
+
+			requestHash, err := hashIdempotentBody(r, config.MaxBodyBytes)
+			if errors.Is(err, ErrBodyTooLarge) {
+				WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+
+			stored, err := config.Store.Begin(key)
+			if errors.Is(err, ErrIdempotencyInProgress) {
+				WriteErrorResponse(w, http.StatusConflict, "Request with this idempotency key is in progress")
+				return
+			}
+			if err != nil {
+				WriteErrorResponse(w, http.StatusInternalServerError, "Idempotency store unavailable")
+				return
+			}
+			if stored != nil {
+				if stored.RequestHash != requestHash {
+					WriteErrorResponse(w, http.StatusUnprocessableEntity, "Idempotency key reused with a different request body")
+					return
+				}
+				replayResponse(w, stored)
+				return
+			}
+
+			before := w.Header().Clone()
+			recorder := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				if !completed {
+					config.Store.Release(key)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status >= 500 {
+				return
+			}
+			config.Store.Complete(key, &IdempotentResponse{
+				StatusCode:  recorder.status,
+				Header:      handlerHeaders(before, w.Header()),
+				Body:        recorder.body.Bytes(),
+				RequestHash: requestHash,
+			})
+			completed = true
+		})
+	}
+}
+
+// hashIdempotentBody returns the hex SHA-256 of the request body and restores the body
+func hashIdempotentBody(r *http.Request, maxBytes int64) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+		r.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		if int64(len(body)) > maxBytes {
+			return "", ErrBodyTooLarge
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// replayExcludedHeaders describe the encoding applied by outer middleware,
+// which the stored body does not carry
+var replayExcludedHeaders = []string{"Content-Encoding", "Content-Length", "Vary"}
+
+// handlerHeaders returns the headers set by the wrapped handler, leaving out
+// those outer middleware put on the shared header map before or around it
+func handlerHeaders(before, after http.Header) http.Header {
+	header := make(http.Header)
+	for key, values := range after {
+		if !slices.Equal(before[key], values) {
+			header[key] = slices.Clone(values)
+		}
+	}
+	for _, key := range replayExcludedHeaders {
+		header.Del(key)
+	}
+	return header
+}
+
+// replayResponse writes a stored response
+func replayResponse(w http.ResponseWriter, stored *IdempotentResponse) {
+	for key, values := range stored.Header {
+		w.Header()[key] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	w.Write(stored.Body)
+}
+
+// responseCapture copies the status and body written by a handler
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+// Write records and forwards the body
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
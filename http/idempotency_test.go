@@ -0,0 +1,301 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+func TestClient_IdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	var attempts int32
+	keys := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(DefaultIdempotencyKeyHeader)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithIdempotencyKeys(providers.NewSimpleIDGenerator("idem")),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	resp, err := client.Post("/bookings", map[string]string{"flight": "SU100"}, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	first, second := <-keys, <-keys
+	if first == "" || first != second {
+		t.Errorf("Idempotency keys = %q, %q, want the same non-empty key", first, second)
+	}
+
+	resp, err = client.Get("/bookings", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if key := <-keys; key != "" {
+		t.Errorf("GET idempotency key = %q, want none", key)
+	}
+}
+
+func TestClient_IdempotencyKeyFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultIdempotencyKeyHeader) != "booking-42" {
+			t.Errorf("Expected idempotency key booking-42, got %v", r.Header.Get(DefaultIdempotencyKeyHeader))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := ContextWithIdempotencyKey(context.Background(), "booking-42")
+
+	resp, err := client.PostContext(ctx, "/bookings", nil, nil)
+	if err != nil {
+		t.Fatalf("Client.PostContext() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestIdempotencyMiddleware_Replay(t *testing.T) {
+	var calls int32
+	handler := IdempotencyMiddleware(DefaultIdempotencyConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		WriteJSONResponse(w, http.StatusCreated, map[string]int32{"booking": n})
+	}))
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(`{}`))
+		req.Header.Set(DefaultIdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("key-1")
+	replayed := send("key-1")
+	other := send("key-2")
+
+	if calls != 2 {
+		t.Errorf("Handler calls = %d, want 2", calls)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.Body.String() {
+		t.Errorf("Replayed response = %d %s, want %d %s", replayed.Code, replayed.Body, first.Code, first.Body)
+	}
+	if replayed.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Replayed response missing %s header", IdempotentReplayedHeader)
+	}
+	if other.Body.String() == first.Body.String() {
+		t.Errorf("Different key replayed response %s", other.Body)
+	}
+}
+
+func TestIdempotencyMiddleware_ConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := IdempotencyMiddleware(DefaultIdempotencyConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(DefaultIdempotencyKeyHeader, "pay-1")
+		return req
+	}
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest())
+		close(done)
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest())
+	close(release)
+	<-done
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Concurrent duplicate status = %v, want %v", w.Code, http.StatusConflict)
+	}
+}
+
+func TestIdempotencyMiddleware_ServerErrorNotStored(t *testing.T) {
+	var calls int32
+	handler := IdempotencyMiddleware(DefaultIdempotencyConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPut, "/bookings/1", nil)
+		req.Header.Set(DefaultIdempotencyKeyHeader, "key-1")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls != 2 {
+		t.Errorf("Handler calls = %d, want 2 after server error", calls)
+	}
+}
+
+func TestInMemoryIdempotencyStore_TTL(t *testing.T) {
+	clock := newFakeClock()
+	store := NewInMemoryIdempotencyStore(time.Hour, clock)
+
+	if _, err := store.Begin("key"); err != nil {
+		t.Fatalf("InMemoryIdempotencyStore.Begin() error = %v", err)
+	}
+	store.Complete("key", &IdempotentResponse{StatusCode: http.StatusCreated})
+
+	if resp, _ := store.Begin("key"); resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Errorf("InMemoryIdempotencyStore.Begin() = %v, want stored response", resp)
+	}
+
+	clock.SetTime(clock.Now().Add(time.Hour))
+	if resp, err := store.Begin("key"); resp != nil || err != nil {
+		t.Errorf("InMemoryIdempotencyStore.Begin() after TTL = %v, %v, want new reservation", resp, err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("InMemoryIdempotencyStore.Len() = %d, want 1", store.Len())
+	}
+}
+
+func TestIdempotencyMiddleware_RejectsDifferentBody(t *testing.T) {
+	var calls int32
+	handler := IdempotencyMiddleware(DefaultIdempotencyConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set(DefaultIdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	send(`{"seat":"1A"}`)
+	if status := send(`{"seat":"2B"}`); status != http.StatusUnprocessableEntity {
+		t.Errorf("Reused key with different body status = %v, want %v", status, http.StatusUnprocessableEntity)
+	}
+	if status := send(`{"seat":"1A"}`); status != http.StatusCreated || calls != 1 {
+		t.Errorf("Replay status = %v after %d calls, want %v after 1", status, calls, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyMiddleware_Scope(t *testing.T) {
+	var calls int32
+	config := DefaultIdempotencyConfig()
+	config.Scope = func(r *http.Request) string {
+		return r.Header.Get("X-User-ID")
+	}
+	handler := IdempotencyMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", nil)
+		req.Header.Set(DefaultIdempotencyKeyHeader, "key-1")
+		req.Header.Set("X-User-ID", user)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls != 2 {
+		t.Errorf("Handler calls = %d, want 2 for two users", calls)
+	}
+}
+
+func TestClient_WithIdempotencyKeyHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Idempotency-Key") != "booking-42" || r.Header.Get(DefaultIdempotencyKeyHeader) != "" {
+			t.Errorf("Idempotency headers = %v", r.Header)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithIdempotencyKeyHeader("X-Idempotency-Key"))
+	ctx := ContextWithIdempotencyKey(context.Background(), "booking-42")
+
+	resp, err := client.PostContext(ctx, "/bookings", nil, nil)
+	if err != nil {
+		t.Fatalf("Client.PostContext() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestIdempotencyMiddleware_ReplayBehindOuterMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"booking":"SU100"}`, 200)
+	handler := RequestIDMiddleware(nil, "")(
+		CompressionMiddleware(CompressionConfig{MinSize: 1})(
+			IdempotencyMiddleware(DefaultIdempotencyConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(body))
+			})),
+		),
+	)
+
+	send := func(requestID, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(`{}`))
+		req.Header.Set(DefaultIdempotencyKeyHeader, "key-1")
+		req.Header.Set(DefaultRequestIDHeader, requestID)
+		req.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	send("req-A", "gzip")
+
+	gzipped := send("req-B", "gzip")
+	if gzipped.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("Response was not replayed")
+	}
+	if id := gzipped.Header().Get(DefaultRequestIDHeader); id != "req-B" {
+		t.Errorf("Replayed %s = %q, want req-B", DefaultRequestIDHeader, id)
+	}
+	gz, err := gzip.NewReader(gzipped.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	decoded, err := io.ReadAll(gz)
+	if err != nil || string(decoded) != body {
+		t.Errorf("Replayed gzip body = %q, %v, want the handler body", decoded, err)
+	}
+	if got := gzipped.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Replayed Content-Type = %q, want application/json", got)
+	}
+
+	identity := send("req-C", "identity")
+	if encoding := identity.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("Replayed identity Content-Encoding = %q, want none", encoding)
+	}
+	if identity.Body.String() != body {
+		t.Errorf("Replayed identity body differs from the handler body")
+	}
+}
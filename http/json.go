@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// GetJSON performs a GET request and decodes the JSON response into T
+func GetJSON[T any](ctx context.Context, c *Client, endpoint string, headers map[string]string) (T, error) {
+	return doJSON[T](ctx, c, http.MethodGet, endpoint, nil, headers)
+}
+
+// PostJSON performs a POST request with JSON body and decodes the JSON response into Resp
+func PostJSON[Req, Resp any](ctx context.Context, c *Client, endpoint string, body Req, headers map[string]string) (Resp, error) {
+	return doJSON[Resp](ctx, c, http.MethodPost, endpoint, body, headers)
+}
+
+// PutJSON performs a PUT request with JSON body and decodes the JSON response into Resp
+func PutJSON[Req, Resp any](ctx context.Context, c *Client, endpoint string, body Req, headers map[string]string) (Resp, error) {
+	return doJSON[Resp](ctx, c, http.MethodPut, endpoint, body, headers)
+}
+
+// DeleteJSON performs a DELETE request and decodes the JSON response into T
+func DeleteJSON[T any](ctx context.Context, c *Client, endpoint string, headers map[string]string) (T, error) {
+	return doJSON[T](ctx, c, http.MethodDelete, endpoint, nil, headers)
+}
+
+// doJSON sends the request, checks status and content type, and decodes the response
+func doJSON[T any](ctx context.Context, c *Client, method, endpoint string, body interface{}, headers map[string]string) (T, error) {
+	var result T
+
+	jsonHeaders := map[string]string{
+		"Accept": "application/json",
+	}
+	for key, value := range headers {
+		jsonHeaders[key] = value
+	}
+
+	resp, err := c.Do(ctx, method, endpoint, body, jsonHeaders)
+	if err != nil {
+		return result, err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		return result, NewHTTPError(resp)
+	}
+
+	if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
+		resp.Body.Close()
+		return result, nil
+	}
+
+	if err := ParseJSONResponse(resp, &result, RequireJSONContentType()); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// checkJSONContentType verifies that the media type is JSON
+func checkJSONContentType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("unexpected content type %q: %w", contentType, err)
+	}
+
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testFlight struct {
+	ID    int    `json:"id"`
+	Route string `json:"route"`
+}
+
+func TestGetJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("Expected Accept application/json, got %v", r.Header.Get("Accept"))
+		}
+		WriteJSONResponse(w, http.StatusOK, testFlight{ID: 1, Route: "MOW-LED"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	flight, err := GetJSON[testFlight](context.Background(), client, "/flights/1", nil)
+	if err != nil {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+
+	if flight.ID != 1 || flight.Route != "MOW-LED" {
+		t.Errorf("GetJSON() = %+v, want {ID:1 Route:MOW-LED}", flight)
+	}
+}
+
+func TestPostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req testFlight
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		req.ID = 42
+		WriteJSONResponse(w, http.StatusCreated, req)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	flight, err := PostJSON[testFlight, testFlight](context.Background(), client, "/flights", testFlight{Route: "LED-AER"}, nil)
+	if err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+
+	if flight.ID != 42 || flight.Route != "LED-AER" {
+		t.Errorf("PostJSON() = %+v, want {ID:42 Route:LED-AER}", flight)
+	}
+}
+
+func TestGetJSON_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, http.StatusNotFound, "Flight not found")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := GetJSON[testFlight](context.Background(), client, "/flights/404", nil)
+	if !IsNotFound(err) {
+		t.Fatalf("GetJSON() error = %v, want not found HTTPError", err)
+	}
+}
+
+func TestGetJSON_UnexpectedContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	if _, err := GetJSON[testFlight](context.Background(), client, "/flights/1", nil); err == nil {
+		t.Fatal("GetJSON() expected error for text/html response")
+	}
+}
+
+func TestDeleteJSON_NoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	flight, err := DeleteJSON[testFlight](context.Background(), client, "/flights/1", nil)
+	if err != nil {
+		t.Fatalf("DeleteJSON() error = %v", err)
+	}
+	if flight != (testFlight{}) {
+		t.Errorf("DeleteJSON() = %+v, want zero value", flight)
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sort"
+)
+
+// RoundTripperFunc adapts an ordinary function to http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a RoundTripper with cross-cutting behavior
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// stage orders middleware in the client pipeline, lower stages run first.
+// Retries happen above the pipeline, so every attempt passes through it.
+type stage int
+
+const (
+	// stageCache answers from the response cache before anything else runs
+	stageCache stage = iota
+	// stageCoalesce shares cache misses between concurrent identical requests
+	stageCoalesce
+	// stageTelemetry holds tracing so spans cover the whole attempt
+	stageTelemetry
+	// stageUser holds middleware registered with WithMiddleware
+	stageUser
+	// stageHedge races copies of a request, each passing through later stages
+	stageHedge
+	// stageGuard holds protection such as circuit breakers
+	stageGuard
+	// stageAuth adds credentials right before the request leaves
+	stageAuth
+	// stageDeadline stamps the remaining budget after time spent waiting in earlier stages
+	stageDeadline
+	// stageSign signs the final request including credentials
+	stageSign
+	// stageCompress encodes the body last so signatures cover the uncompressed payload
+	stageCompress
+)
+
+// stagedMiddleware is a middleware bound to its pipeline stage
+type stagedMiddleware struct {
+	stage      stage
+	middleware Middleware
+}
+
+// requestMiddlewareKey is the context key for per-request middleware
+type requestMiddlewareKey struct{}
+
+// WithMiddleware appends middleware to the client pipeline.
+// Middleware registered first runs outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		for _, mw := range middleware {
+			c.use(stageUser, mw)
+		}
+	}
+}
+
+// WithTransport sets the RoundTripper at the bottom of the pipeline
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// ContextWithMiddleware returns a context that applies middleware to requests made with it.
+// Per-request middleware runs outside the client pipeline.
+func ContextWithMiddleware(ctx context.Context, middleware ...Middleware) context.Context {
+	existing, _ := ctx.Value(requestMiddlewareKey{}).([]Middleware)
+	combined := make([]Middleware, 0, len(existing)+len(middleware))
+	combined = append(combined, existing...)
+	combined = append(combined, middleware...)
+	return context.WithValue(ctx, requestMiddlewareKey{}, combined)
+}
+
+// Chain wraps the RoundTripper so that the first middleware runs outermost
+func Chain(transport http.RoundTripper, middleware ...Middleware) http.RoundTripper {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+	return transport
+}
+
+// closeRequestBody closes the request body, which RoundTrip must do even when it fails
+// before sending; otherwise a streamed body's writer never finishes
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// use registers middleware at the given pipeline stage
+func (c *Client) use(s stage, mw Middleware) {
+	c.middleware = append(c.middleware, stagedMiddleware{stage: s, middleware: mw})
+}
+
+// buildTransport assembles the middleware pipeline on top of the base transport
+func (c *Client) buildTransport() http.RoundTripper {
+	base := c.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	staged := make([]stagedMiddleware, len(c.middleware))
+	copy(staged, c.middleware)
+	sort.SliceStable(staged, func(i, j int) bool {
+		return staged[i].stage < staged[j].stage
+	})
+
+	middleware := make([]Middleware, len(staged))
+	for i, sm := range staged {
+		middleware[i] = sm.middleware
+	}
+	pipeline := Chain(base, middleware...)
+
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if perRequest, ok := req.Context().Value(requestMiddlewareKey{}).([]Middleware); ok {
+			return Chain(pipeline, perRequest...).RoundTrip(req)
+		}
+		return pipeline.RoundTrip(req)
+	})
+}
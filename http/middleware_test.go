@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func recordingMiddleware(name string, mu *sync.Mutex, calls *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			*calls = append(*calls, name)
+			mu.Unlock()
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestClient_MiddlewareOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var calls []string
+	client := NewClient(server.URL,
+		WithMiddleware(recordingMiddleware("first", &mu, &calls)),
+		WithMiddleware(recordingMiddleware("second", &mu, &calls)),
+	)
+
+	ctx := ContextWithMiddleware(context.Background(), recordingMiddleware("request", &mu, &calls))
+	resp, err := client.GetContext(ctx, "/test", nil)
+	if err != nil {
+		t.Fatalf("Client.GetContext() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	want := []string{"request", "first", "second"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Middleware calls = %v, want %v", calls, want)
+	}
+}
+
+func TestClient_MiddlewareRunsPerRetryAttempt(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var calls []string
+	client := NewClient(server.URL,
+		WithMiddleware(recordingMiddleware("logging", &mu, &calls)),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if len(calls) != 2 {
+		t.Errorf("Middleware calls = %d, want 2", len(calls))
+	}
+}
+
+func TestClient_WithTransport(t *testing.T) {
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+
+	client := NewClient("https://api.example.com", WithTransport(transport))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusTeapot)
+	}
+}
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request is rejected by the rate limiter
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// DefaultMaxRateLimitBuckets is the default cap on tracked rate limit keys
+const DefaultMaxRateLimitBuckets = 10000
+
+// RateLimitMode selects what happens when no token is available
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks until a token is available or the context is done
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast rejects the request with ErrRateLimited
+	RateLimitFailFast
+)
+
+// RateLimit describes a token bucket refill rate and capacity
+type RateLimit struct {
+	// Rate is the number of requests allowed per second
+	Rate float64
+	// Burst is the bucket capacity
+	Burst int
+}
+
+// RateLimiterConfig configures a RateLimiter
+type RateLimiterConfig struct {
+	// Limit applies to every key without an override
+	Limit RateLimit
+	// Limits overrides Limit for specific keys
+	Limits map[string]RateLimit
+	// Mode selects blocking or fail-fast behavior
+	Mode RateLimitMode
+	// KeyFunc selects the bucket for a request, defaults to one bucket per client
+	KeyFunc func(req *http.Request) string
+	// MaxBuckets caps the number of tracked keys, defaults to DefaultMaxRateLimitBuckets.
+	// Idle buckets are evicted first, then the least recently used ones.
+	MaxBuckets int
+	// Clock supplies the current time and waits for tokens
+	Clock Clock
+}
+
+// RateLimiter limits outbound requests using token buckets per key
+type RateLimiter struct {
+	config  RateLimiterConfig
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket holds the state of a single bucket
+type tokenBucket struct {
+	limit        RateLimit
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	// used is when the bucket was last looked up, for least recently used eviction
+	used time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(*http.Request) string { return "" }
+	}
+	if config.Clock == nil {
+		config.Clock = systemClock
+	}
+	if config.MaxBuckets <= 0 {
+		config.MaxBuckets = DefaultMaxRateLimitBuckets
+	}
+
+	return &RateLimiter{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// WithRateLimiter limits requests with the rate limiter
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.use(stageGuard, limiter.Middleware())
+	}
+}
+
+// KeyByEndpoint returns a key function matching request paths against path.Match patterns.
+// The matched pattern is the key; unmatched requests share the empty key.
+func KeyByEndpoint(patterns ...string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, req.URL.Path); matched {
+				return pattern
+			}
+		}
+		return ""
+	}
+}
+
+// KeyByHeader returns a key function using the value of a request header
+func KeyByHeader(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// Allow takes a token for the key without waiting
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.bucket(key)
+	if bucket == nil {
+		return true
+	}
+
+	now := l.config.Clock.Now()
+	bucket.refill(now)
+	if now.Before(bucket.blockedUntil) || bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// Wait takes a token for the key, waiting until one is available
+func (l *RateLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	bucket := l.bucket(key)
+	if bucket == nil {
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := l.config.Clock.Now()
+	delay := bucket.reserve(now)
+	l.mu.Unlock()
+
+	for delay > 0 {
+		if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+			l.cancel(key)
+			return fmt.Errorf("%w: wait of %v exceeds context deadline", ErrRateLimited, delay)
+		}
+
+		if err := l.config.Clock.Sleep(ctx, delay); err != nil {
+			l.cancel(key)
+			return err
+		}
+		now, delay = l.requeue(key)
+	}
+	return nil
+}
+
+// requeue checks a waiter that woke up; if the bucket was blocked meanwhile,
+// the reservation is returned and a new one is taken behind the block
+func (l *RateLimiter) requeue(key string) (time.Time, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.config.Clock.Now()
+	bucket := l.bucket(key)
+	if bucket == nil || !now.Before(bucket.blockedUntil) {
+		return now, 0
+	}
+
+	bucket.tokens = math.Min(bucket.tokens+1, float64(bucket.limit.Burst))
+	return now, bucket.reserve(now)
+}
+
+// Middleware returns middleware that limits requests and adapts to upstream limit headers
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			key := l.config.KeyFunc(req)
+
+			if l.config.Mode == RateLimitFailFast {
+				if !l.Allow(key) {
+					closeRequestBody(req)
+					return nil, ErrRateLimited
+				}
+			} else if err := l.Wait(req.Context(), key); err != nil {
+				closeRequestBody(req)
+				return nil, err
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err == nil {
+				l.observe(key, resp)
+			}
+			return resp, err
+		})
+	}
+}
+
+// observe adapts the bucket to Retry-After and X-RateLimit-* response headers
+func (l *RateLimiter) observe(key string, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.bucket(key)
+	if bucket == nil {
+		return
+	}
+	now := l.config.Clock.Now()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if delay := retryAfter(resp, now); delay > 0 {
+			bucket.block(now.Add(delay))
+		}
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	bucket.refill(now)
+	if float64(remaining) < bucket.tokens {
+		bucket.tokens = float64(remaining)
+	}
+	if remaining == 0 {
+		if reset, ok := rateLimitReset(resp.Header.Get("X-RateLimit-Reset"), now); ok {
+			bucket.block(reset)
+		}
+	}
+}
+
+// cancel returns a reserved token that was not used
+func (l *RateLimiter) cancel(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket := l.bucket(key); bucket != nil {
+		bucket.tokens = math.Min(bucket.tokens+1, float64(bucket.limit.Burst))
+	}
+}
+
+// bucket returns the bucket for the key, or nil if the key is unlimited; l.mu must be held
+func (l *RateLimiter) bucket(key string) *tokenBucket {
+	if bucket, exists := l.buckets[key]; exists {
+		bucket.used = l.config.Clock.Now()
+		return bucket
+	}
+
+	limit, exists := l.config.Limits[key]
+	if !exists {
+		limit = l.config.Limit
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+
+	now := l.config.Clock.Now()
+	if len(l.buckets) >= l.config.MaxBuckets {
+		l.evict(now)
+	}
+
+	bucket := &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+		used:   now,
+	}
+	l.buckets[key] = bucket
+	return bucket
+}
+
+// evict drops idle buckets, or the least recently used one if none is idle; l.mu must be held
+func (l *RateLimiter) evict(now time.Time) {
+	var oldestKey string
+	var oldest *tokenBucket
+
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.idle(now) {
+			delete(l.buckets, key)
+			continue
+		}
+		if oldest == nil || bucket.used.Before(oldest.used) {
+			oldestKey, oldest = key, bucket
+		}
+	}
+
+	if len(l.buckets) >= l.config.MaxBuckets && oldest != nil {
+		delete(l.buckets, oldestKey)
+	}
+}
+
+// refill adds tokens accumulated since the last update
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*b.limit.Rate, float64(b.limit.Burst))
+		b.last = now
+	}
+}
+
+// reserve takes a token, possibly going into debt, and returns how long to wait for it.
+// While blocked the bucket refills from the end of the block, so queued waiters are spread out after it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.refill(now)
+
+	var delay time.Duration
+	if start := b.last.Sub(now); start > 0 {
+		delay = start
+	}
+	if b.tokens < 1 {
+		delay += time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return delay
+}
+
+// idle reports whether the bucket is full and unblocked, so dropping it loses no state
+func (b *tokenBucket) idle(now time.Time) bool {
+	return b.tokens >= float64(b.limit.Burst) && !now.Before(b.blockedUntil)
+}
+
+// block stops issuing tokens until the given time. Debt from reserved tokens is kept;
+// otherwise one token is ready when the block ends.
+func (b *tokenBucket) block(until time.Time) {
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+		if b.tokens >= 0 {
+			b.tokens = 1
+		}
+		if until.After(b.last) {
+			b.last = until
+		}
+	}
+}
+
+// rateLimitReset parses X-RateLimit-Reset as a Unix timestamp or a number of seconds
+func rateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+
+	// Values this large are absolute Unix timestamps rather than deltas
+	if seconds > 1e9 {
+		return time.Unix(seconds, 0), true
+	}
+	return now.Add(time.Duration(seconds) * time.Second), true
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+func TestRateLimiter_FailFast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 1, Burst: 2},
+		Mode:  RateLimitFailFast,
+		Clock: newFakeClock(),
+	})
+	client := NewClient(server.URL, WithRateLimiter(limiter))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("/test", nil)
+		if err != nil {
+			t.Fatalf("Client.Get() call %d error = %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	if _, err := client.Get("/test", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Client.Get() error = %v, want %v", err, ErrRateLimited)
+	}
+}
+
+func TestRateLimiter_WaitAdvancesClock(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 2, Burst: 1},
+		Clock: clock,
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), ""); err != nil {
+			t.Fatalf("RateLimiter.Wait() call %d error = %v", i+1, err)
+		}
+	}
+
+	if waited := clock.Now().Sub(start); waited != time.Second {
+		t.Errorf("RateLimiter.Wait() waited %v, want %v", waited, time.Second)
+	}
+}
+
+func TestRateLimiter_PerKeyLimits(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 1, Burst: 1},
+		Limits: map[string]RateLimit{
+			"/search/*": {Rate: 1, Burst: 3},
+		},
+		Clock: newFakeClock(),
+	})
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("/search/*") {
+			t.Errorf("RateLimiter.Allow(search) call %d = false, want true", i+1)
+		}
+	}
+	if limiter.Allow("/search/*") {
+		t.Error("RateLimiter.Allow(search) after burst = true, want false")
+	}
+
+	if !limiter.Allow("token-a") {
+		t.Error("RateLimiter.Allow(token-a) = false, want true")
+	}
+	if !limiter.Allow("token-b") {
+		t.Error("RateLimiter.Allow(token-b) = false, want true")
+	}
+}
+
+func TestRateLimiter_HonorsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 100, Burst: 10},
+		Mode:  RateLimitFailFast,
+		Clock: clock,
+	})
+	client := NewClient(server.URL, WithRateLimiter(limiter))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if limiter.Allow("") {
+		t.Error("RateLimiter.Allow() during Retry-After = true, want false")
+	}
+
+	clock.SetTime(clock.Now().Add(30 * time.Second))
+	if !limiter.Allow("") {
+		t.Error("RateLimiter.Allow() after Retry-After = false, want true")
+	}
+}
+
+func TestRateLimiter_HonorsRateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "5")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit:   RateLimit{Rate: 100, Burst: 10},
+		KeyFunc: KeyByHeader("X-Api-Token"),
+		Clock:   clock,
+	})
+	client := NewClient(server.URL, WithRateLimiter(limiter))
+	headers := map[string]string{
+		"X-Api-Token": "partner",
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("/test", headers)
+		if err != nil {
+			t.Fatalf("Client.Get() call %d error = %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	if waited := clock.Now().Sub(start); waited != 5*time.Second {
+		t.Errorf("Client.Get() waited %v, want %v", waited, 5*time.Second)
+	}
+}
+
+func TestKeyByEndpoint(t *testing.T) {
+	keyFunc := KeyByEndpoint("/search/*", "/prices/*")
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/prices/MOW", nil)
+	if key := keyFunc(req); key != "/prices/*" {
+		t.Errorf("KeyByEndpoint() = %v, want /prices/*", key)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/airports", nil)
+	if key := keyFunc(req); key != "" {
+		t.Errorf("KeyByEndpoint() = %v, want empty", key)
+	}
+}
+
+func TestRateLimiter_EvictsBuckets(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit:      RateLimit{Rate: 1, Burst: 1},
+		Mode:       RateLimitFailFast,
+		MaxBuckets: 2,
+		Clock:      clock,
+	})
+
+	limiter.Allow("alice")
+	clock.SetTime(clock.Now().Add(100 * time.Millisecond))
+	limiter.Allow("bob")
+	clock.SetTime(clock.Now().Add(100 * time.Millisecond))
+
+	// Both buckets are in use, so the least recently used one makes room
+	limiter.Allow("carol")
+	if _, exists := limiter.buckets["alice"]; exists || len(limiter.buckets) != 2 {
+		t.Errorf("RateLimiter buckets = %d with alice present = %v, want 2 without alice", len(limiter.buckets), exists)
+	}
+	if limiter.Allow("bob") {
+		t.Error("RateLimiter.Allow(bob) = true, want false for an active bucket")
+	}
+
+	// Refilled buckets carry no state and are all dropped
+	clock.SetTime(clock.Now().Add(time.Minute))
+	limiter.Allow("dave")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("RateLimiter buckets = %d after idle eviction, want 1", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiter_SpreadsWaitersAfterBlock(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 1, Burst: 1},
+		Clock: clock,
+	})
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"10"}}}
+	limiter.observe("", resp)
+
+	delays := make([]time.Duration, 5)
+	limiter.mu.Lock()
+	for i := range delays {
+		delays[i] = limiter.bucket("").reserve(start)
+	}
+	limiter.mu.Unlock()
+
+	for i, delay := range delays {
+		if want := time.Duration(10+i) * time.Second; delay != want {
+			t.Errorf("Waiter %d delay = %v, want %v", i, delay, want)
+		}
+	}
+}
+
+// blockingClock runs onSleep before the first sleep, e.g. to deliver a 429 while a waiter sleeps
+type blockingClock struct {
+	*providers.FixedTimeProvider
+	onSleep func()
+}
+
+func (c *blockingClock) Sleep(ctx context.Context, d time.Duration) error {
+	if onSleep := c.onSleep; onSleep != nil {
+		c.onSleep = nil
+		onSleep()
+	}
+	return c.FixedTimeProvider.Sleep(ctx, d)
+}
+
+func TestRateLimiter_WaiterRequeuesBehindBlock(t *testing.T) {
+	clock := &blockingClock{FixedTimeProvider: newFakeClock()}
+	start := clock.Now()
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 1, Burst: 1},
+		Clock: clock,
+	})
+	clock.onSleep = func() {
+		limiter.observe("", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"10"}}})
+	}
+
+	for i, want := range []time.Duration{0, 11 * time.Second, 12 * time.Second} {
+		if err := limiter.Wait(context.Background(), ""); err != nil {
+			t.Fatalf("RateLimiter.Wait() call %d error = %v", i+1, err)
+		}
+		if waited := clock.Now().Sub(start); waited != want {
+			t.Errorf("RateLimiter.Wait() call %d returned after %v, want %v", i+1, waited, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RequestBuilder assembles a request step by step before sending it
+type RequestBuilder struct {
+	client      *Client
+	method      string
+	endpoint    string
+	query       url.Values
+	headers     http.Header
+	body        io.Reader
+	contentType string
+	err         error
+}
+
+// NewRequest starts building a request for the endpoint
+func (c *Client) NewRequest(method, endpoint string) *RequestBuilder {
+	return &RequestBuilder{
+		client:   c,
+		method:   method,
+		endpoint: endpoint,
+		query:    make(url.Values),
+		headers:  make(http.Header),
+	}
+}
+
+// Query adds a query parameter, keeping any already present in the endpoint
+func (b *RequestBuilder) Query(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Header sets a request header
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.headers.Set(key, value)
+	return b
+}
+
+// Headers sets several request headers
+func (b *RequestBuilder) Headers(headers map[string]string) *RequestBuilder {
+	for key, value := range headers {
+		b.headers.Set(key, value)
+	}
+	return b
+}
+
+// JSON sets a JSON encoded body
+func (b *RequestBuilder) JSON(body interface{}) *RequestBuilder {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		b.err = fmt.Errorf("failed to marshal JSON body: %w", err)
+		return b
+	}
+
+	b.body = bytes.NewReader(jsonBody)
+	b.contentType = "application/json"
+	return b
+}
+
+// Form sets a URL encoded form body
+func (b *RequestBuilder) Form(values url.Values) *RequestBuilder {
+	b.body = strings.NewReader(values.Encode())
+	b.contentType = "application/x-www-form-urlencoded"
+	return b
+}
+
+// Body sets a raw body; readers other than bytes and strings readers cannot be retried
+func (b *RequestBuilder) Body(body io.Reader) *RequestBuilder {
+	b.body = body
+	b.contentType = ""
+	return b
+}
+
+// Do sends the request
+func (b *RequestBuilder) Do(ctx context.Context) (*http.Response, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	endpoint, err := mergeQuery(b.endpoint, b.query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := b.client.newRequest(ctx, b.method, endpoint, b.body)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.contentType != "" {
+		req.Header.Set("Content-Type", b.contentType)
+	}
+	for key, values := range b.headers {
+		req.Header[key] = values
+	}
+
+	return b.client.send(req, endpoint)
+}
+
+// mergeQuery adds query parameters to those already present in the endpoint
+func mergeQuery(endpoint string, query url.Values) (string, error) {
+	if len(query) == 0 {
+		return endpoint, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse endpoint %q: %w", endpoint, err)
+	}
+
+	merged := u.Query()
+	for key, values := range query {
+		merged[key] = append(merged[key], values...)
+	}
+	u.RawQuery = merged.Encode()
+
+	return u.String(), nil
+}
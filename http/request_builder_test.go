@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilder_QueryAndJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("Expected PATCH request, got %v", r.Method)
+		}
+		query := r.URL.Query()
+		if query.Get("currency") != "RUB" || query.Get("origin") != "MOW & LED" {
+			t.Errorf("Query = %v, want currency=RUB and origin=MOW & LED", query)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %v", r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("X-Client") != "bot" {
+			t.Errorf("Expected X-Client header, got %v", r.Header.Get("X-Client"))
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if body["price"] != float64(4200) {
+			t.Errorf("Expected body price=4200, got %v", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	resp, err := client.NewRequest(http.MethodPatch, "/subscriptions/1?currency=RUB").
+		Query("origin", "MOW & LED").
+		Header("X-Client", "bot").
+		JSON(map[string]interface{}{"price": 4200}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("RequestBuilder.Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("RequestBuilder.Do() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestRequestBuilder_Form(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		if r.PostForm.Get("chat_id") != "42" {
+			t.Errorf("Expected chat_id=42, got %v", r.PostForm)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	resp, err := client.NewRequest(http.MethodPost, "/sendMessage").
+		Form(url.Values{"chat_id": {"42"}}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("RequestBuilder.Do() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRequestBuilder_Body(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "raw payload" {
+			t.Errorf("Expected raw payload, got %v", string(body))
+		}
+		if r.Header.Get("Content-Type") != "text/plain" {
+			t.Errorf("Expected Content-Type text/plain, got %v", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	resp, err := client.NewRequest(http.MethodPut, "/raw").
+		Body(strings.NewReader("raw payload")).
+		Header("Content-Type", "text/plain").
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("RequestBuilder.Do() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRequestBuilder_JSONError(t *testing.T) {
+	client := NewClient("https://api.example.com")
+
+	_, err := client.NewRequest(http.MethodPost, "/test").JSON(make(chan int)).Do(context.Background())
+	if err == nil {
+		t.Fatal("RequestBuilder.Do() expected error for unmarshalable body")
+	}
+}
+
+func TestClient_ExtraVerbs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	calls := map[string]func() (*http.Response, error){
+		http.MethodPatch:   func() (*http.Response, error) { return client.Patch("/test", map[string]string{"k": "v"}, nil) },
+		http.MethodHead:    func() (*http.Response, error) { return client.Head("/test", nil) },
+		http.MethodOptions: func() (*http.Response, error) { return client.Options("/test", nil) },
+	}
+
+	for method, call := range calls {
+		resp, err := call()
+		if err != nil {
+			t.Fatalf("Client %s error = %v", method, err)
+		}
+		resp.Body.Close()
+
+		if resp.Header.Get("X-Method") != method {
+			t.Errorf("Client %s reached server as %v", method, resp.Header.Get("X-Method"))
+		}
+	}
+}
+
+func TestMergeQuery(t *testing.T) {
+	merged, err := mergeQuery("/search?from=MOW", url.Values{"to": {"LED"}, "from": {"VKO"}})
+	if err != nil {
+		t.Fatalf("mergeQuery() error = %v", err)
+	}
+
+	want := "/search?from=MOW&from=VKO&to=LED"
+	if merged != want {
+		t.Errorf("mergeQuery() = %v, want %v", merged, want)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+// DefaultRequestIDHeader is the header used to carry request IDs
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key for the request ID
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in the context, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID generates request IDs for calls whose context does not carry one
+func WithRequestID(generator interfaces.IDGenerator) Option {
+	return func(c *Client) {
+		c.requestIDGenerator = generator
+	}
+}
+
+// WithRequestIDHeader sets the header used to send request IDs
+func WithRequestIDHeader(name string) Option {
+	return func(c *Client) {
+		c.requestIDHeader = name
+	}
+}
+
+// setRequestID attaches the request ID once per logical call so retries share it
+func (c *Client) setRequestID(req *http.Request) {
+	if req.Header.Get(c.requestIDHeader) != "" {
+		return
+	}
+
+	id := RequestIDFromContext(req.Context())
+	if id == "" && c.requestIDGenerator != nil {
+		id = c.requestIDGenerator.Generate()
+	}
+	if id != "" {
+		req.Header.Set(c.requestIDHeader, id)
+	}
+}
+
+// RequestIDMiddleware reads the request ID from the header or generates one,
+// stores it in the request context, and echoes it in the response.
+// An empty header defaults to DefaultRequestIDHeader and a nil generator to UUIDs.
+func RequestIDMiddleware(generator interfaces.IDGenerator, header string) func(http.Handler) http.Handler {
+	if generator == nil {
+		generator = providers.NewUUIDGenerator()
+	}
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if id == "" {
+				id = generator.Generate()
+			}
+
+			w.Header().Set(header, id)
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+		})
+	}
+}
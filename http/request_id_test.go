@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+func TestClient_RequestIDFromGenerator(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(DefaultRequestIDHeader))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithRequestID(providers.NewSimpleIDGenerator("req")),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithClock(newFakeClock()),
+	)
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if len(ids) != 2 || ids[0] != "req-1" || ids[1] != "req-1" {
+		t.Errorf("Request IDs = %v, want [req-1 req-1]", ids)
+	}
+}
+
+func TestClient_RequestIDFromContext(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Correlation-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL,
+		WithRequestID(providers.NewSimpleIDGenerator("req")),
+		WithRequestIDHeader("X-Correlation-ID"),
+	)
+
+	ctx := ContextWithRequestID(context.Background(), "incoming-42")
+	resp, err := client.GetContext(ctx, "/test", nil)
+	if err != nil {
+		t.Fatalf("Client.GetContext() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got != "incoming-42" {
+		t.Errorf("Request ID = %v, want incoming-42", got)
+	}
+}
+
+func TestClient_NoRequestIDByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(DefaultRequestIDHeader); id != "" {
+			t.Errorf("Expected no request ID, got %v", id)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(server.URL).Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var fromContext string
+	handler := RequestIDMiddleware(providers.NewPrefixedIDGenerator("req"), "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = RequestIDFromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if !strings.HasPrefix(fromContext, "req-") {
+		t.Errorf("RequestIDFromContext() = %v, want prefix req-", fromContext)
+	}
+	if w.Header().Get(DefaultRequestIDHeader) != fromContext {
+		t.Errorf("Response request ID = %v, want %v", w.Header().Get(DefaultRequestIDHeader), fromContext)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/test", nil)
+	r.Header.Set(DefaultRequestIDHeader, "upstream-7")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if fromContext != "upstream-7" {
+		t.Errorf("RequestIDFromContext() = %v, want upstream-7", fromContext)
+	}
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+// Clock provides the current time and waits between retry attempts
+type Clock interface {
+	interfaces.TimeProvider
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// systemClock is the default Clock and TimeProvider backed by the system time
+var systemClock Clock = &providers.SystemTimeProvider{}
+
+// RetryPolicy configures how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one
+	MaxAttempts int
+	// BaseDelay is the backoff delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff delay
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest Retry-After honored, defaults to MaxDelay.
+	// Responses asking for a longer wait are returned without retrying.
+	MaxRetryAfter time.Duration
+	// RetryableStatusCodes lists response codes that trigger a retry
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most upstreams
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   3,
+		BaseDelay:     100 * time.Millisecond,
+		MaxDelay:      5 * time.Second,
+		MaxRetryAfter: 30 * time.Second,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy enables retries with the given policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithClock sets the clock used for retry delays
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// do sends the request, retrying according to the client's retry policy.
+// With an endpoint pool each attempt is routed to a base URL chosen for it.
+func (c *Client) do(req *http.Request, endpoint string) (*http.Response, error) {
+	maxAttempts := 1
+	if c.retryPolicy != nil && c.retryPolicy.MaxAttempts > 1 {
+		maxAttempts = c.retryPolicy.MaxAttempts
+	}
+
+	ctx := req.Context()
+	tried := make(map[*upstream]bool)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(req, endpoint, tried)
+		if attempt >= maxAttempts || ctx.Err() != nil || !c.retryPolicy.shouldRetry(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// The body has been consumed and cannot be replayed
+			return resp, err
+		}
+
+		wait := retryAfter(resp, c.clock.Now())
+		if wait > c.retryPolicy.maxRetryAfter() {
+			return resp, err
+		}
+
+		delay := c.retryPolicy.backoff(attempt, wait)
+		if resp != nil {
+			drainBody(resp)
+		}
+
+		if err := c.clock.Sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// attempt sends the request once, routing it through the endpoint pool if configured
+func (c *Client) attempt(req *http.Request, endpoint string, tried map[*upstream]bool) (*http.Response, error) {
+	client := c.httpClient
+	if isStreaming(req.Context()) {
+		client = c.streamClient
+	}
+
+	if c.endpoints == nil {
+		return client.Do(req)
+	}
+
+	release, err := c.route(req, endpoint, tried)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	release(resp, err)
+	return resp, err
+}
+
+// shouldRetry reports whether the attempt outcome is retryable
+func (p *RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return isRetryableError(err)
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableError reports whether a transport error is worth another attempt
+func isRetryableError(err error) bool {
+	return !isRejectedLocally(err)
+}
+
+// isRejectedLocally reports whether the pipeline refused the request before sending it
+func isRejectedLocally(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrBudgetExhausted) ||
+		errors.Is(err, ErrBulkheadFull) ||
+		errors.Is(err, ErrLimitExceeded)
+}
+
+// isLocalFailure reports whether the pipeline failed the request without involving the upstream,
+// so the outcome says nothing about upstream health
+func isLocalFailure(err error) bool {
+	return isRejectedLocally(err) ||
+		errors.Is(err, errAuthenticationFailed) ||
+		errors.Is(err, errSigningFailed)
+}
+
+// maxRetryAfter returns the longest Retry-After wait the policy honors
+func (p *RetryPolicy) maxRetryAfter() time.Duration {
+	if p.MaxRetryAfter > 0 {
+		return p.MaxRetryAfter
+	}
+	return p.MaxDelay
+}
+
+// backoff returns the delay before the next attempt using full jitter.
+// A Retry-After delay is used as is, capped by maxRetryAfter.
+func (p *RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if limit := p.maxRetryAfter(); limit > 0 && retryAfter > limit {
+			return limit
+		}
+		return retryAfter
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
+
+// drainBody discards the rest of the body so the connection can be reused
+func drainBody(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+func newFakeClock() *providers.FixedTimeProvider {
+	return providers.NewFixedTimeProvider(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)).(*providers.FixedTimeProvider)
+}
+
+func TestClient_RetryReplaysBody(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if body["key"] != "value" {
+			t.Errorf("Expected body key=value, got %v", body)
+		}
+
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	client := NewClient(server.URL, WithRetryPolicy(DefaultRetryPolicy()), WithClock(clock))
+
+	resp, err := client.Post("/create", map[string]interface{}{"key": "value"}, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Client.Post() status = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+	if attempts != 3 {
+		t.Errorf("Client.Post() attempts = %d, want 3", attempts)
+	}
+	if waited := clock.Now().Sub(start); waited > 300*time.Millisecond {
+		t.Errorf("Client.Post() waited %v, want at most %v", waited, 300*time.Millisecond)
+	}
+}
+
+func TestClient_RetryHonorsRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	client := NewClient(server.URL, WithRetryPolicy(DefaultRetryPolicy()), WithClock(clock))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if waited := clock.Now().Sub(start); waited != 7*time.Second {
+		t.Errorf("Client.Get() waited %v, want %v", waited, 7*time.Second)
+	}
+}
+
+func TestClient_RetryStopsAfterMaxAttempts(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = 4
+	client := NewClient(server.URL, WithRetryPolicy(policy), WithClock(newFakeClock()))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusBadGateway)
+	}
+	if attempts != 4 {
+		t.Errorf("Client.Get() attempts = %d, want 4", attempts)
+	}
+}
+
+func TestClient_NoRetryOnClientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(DefaultRetryPolicy()), WithClock(newFakeClock()))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if attempts != 1 {
+		t.Errorf("Client.Get() attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := policy.backoff(attempt, 0)
+		if delay < 0 || delay > time.Second {
+			t.Errorf("RetryPolicy.backoff(%d) = %v, want within [0, %v]", attempt, delay, time.Second)
+		}
+	}
+
+	if delay := policy.backoff(1, 500*time.Millisecond); delay != 500*time.Millisecond {
+		t.Errorf("RetryPolicy.backoff() with Retry-After = %v, want %v", delay, 500*time.Millisecond)
+	}
+	if delay := policy.backoff(1, 3*time.Second); delay != time.Second {
+		t.Errorf("RetryPolicy.backoff() with long Retry-After = %v, want %v", delay, time.Second)
+	}
+
+	policy.MaxRetryAfter = time.Minute
+	if delay := policy.backoff(1, 3*time.Second); delay != 3*time.Second {
+		t.Errorf("RetryPolicy.backoff() with MaxRetryAfter = %v, want %v", delay, 3*time.Second)
+	}
+}
+
+func TestClient_RetryGivesUpOnLongRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	client := NewClient(server.URL, WithRetryPolicy(DefaultRetryPolicy()), WithClock(clock))
+
+	resp, err := client.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts != 1 {
+		t.Errorf("Client.Get() status = %v after %d attempts, want 503 after 1", resp.StatusCode, attempts)
+	}
+	if waited := clock.Now().Sub(start); waited != 0 {
+		t.Errorf("Client.Get() waited %v, want 0", waited)
+	}
+}
+
+func TestClient_RetryStopsOnCanceledContext(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+	client := NewClient(server.URL, WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetContext(ctx, "/test", nil)
+	if err == nil {
+		t.Fatal("Client.GetContext() expected error for canceled retry wait")
+	}
+	if attempts != 1 {
+		t.Errorf("Client.GetContext() attempts = %d, want 1", attempts)
+	}
+}
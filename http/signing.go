@@ -0,0 +1,229 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+)
+
+const (
+	// DefaultSignatureHeader carries the hex encoded HMAC-SHA256 signature
+	DefaultSignatureHeader = "X-Signature"
+	// DefaultSignatureTimestampHeader carries the Unix timestamp used in the signature
+	DefaultSignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature is missing or does not match
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignatureExpired is returned when the signature timestamp is outside the replay window
+	ErrSignatureExpired = errors.New("signature timestamp outside replay window")
+	// ErrUnsignableBody is returned for streamed bodies that cannot be read twice
+	ErrUnsignableBody = errors.New("request body cannot be signed")
+
+	// errSigningFailed wraps errors from reading the body to sign
+	errSigningFailed = errors.New("failed to read body for signing")
+)
+
+// HMACConfig configures request signing and verification
+type HMACConfig struct {
+	Secret []byte
+	// SignatureHeader defaults to DefaultSignatureHeader
+	SignatureHeader string
+	// TimestampHeader defaults to DefaultSignatureTimestampHeader
+	TimestampHeader string
+	// ReplayWindow is the maximum accepted clock difference when verifying, defaults to 5 minutes
+	ReplayWindow time.Duration
+	// MaxBodyBytes limits the body read when verifying and the body buffered when signing
+	// a request without GetBody, defaults to 10 MB
+	MaxBodyBytes int64
+	// TimeProvider supplies the current time, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+}
+
+// withDefaults fills in unset configuration values
+func (c HMACConfig) withDefaults() HMACConfig {
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = DefaultSignatureHeader
+	}
+	if c.TimestampHeader == "" {
+		c.TimestampHeader = DefaultSignatureTimestampHeader
+	}
+	if c.ReplayWindow <= 0 {
+		c.ReplayWindow = 5 * time.Minute
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = 10 << 20
+	}
+	if c.TimeProvider == nil {
+		c.TimeProvider = systemClock
+	}
+	return c
+}
+
+// CanonicalRequest builds the string that is signed on both sides:
+// method, escaped path, sorted query, timestamp and hex SHA-256 of the body, one per line.
+func CanonicalRequest(method, escapedPath, rawQuery, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return canonicalRequest(method, escapedPath, rawQuery, timestamp, hex.EncodeToString(bodyHash[:]))
+}
+
+// canonicalRequest builds the canonical string from an already computed body hash
+func canonicalRequest(method, escapedPath, rawQuery, timestamp, bodyHash string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err == nil {
+		rawQuery = query.Encode()
+	}
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		escapedPath,
+		rawQuery,
+		timestamp,
+		bodyHash,
+	}, "\n")
+}
+
+// SignCanonical returns the hex encoded HMAC-SHA256 of the canonical string
+func SignCanonical(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WithHMACSigning signs every request attempt with HMAC-SHA256
+func WithHMACSigning(config HMACConfig) Option {
+	return func(c *Client) {
+		c.use(stageSign, HMACSigner(config))
+	}
+}
+
+// HMACSigner returns middleware that signs a copy of each request.
+// Bodies with GetBody are hashed from a second copy without buffering; other bodies are
+// buffered up to MaxBodyBytes. Streamed bodies of unknown length, such as
+// Client.PostMultipart uploads, fail with ErrUnsignableBody.
+func HMACSigner(config HMACConfig) Middleware {
+	config = config.withDefaults()
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			bodyHash, buffered, err := hashRequestBody(req, config.MaxBodyBytes)
+			if err != nil {
+				closeRequestBody(req)
+				return nil, fmt.Errorf("%w: %w", errSigningFailed, err)
+			}
+
+			signed := req.Clone(req.Context())
+			if buffered != nil {
+				signed.Body = buffered
+			}
+			timestamp := strconv.FormatInt(config.TimeProvider.Now().Unix(), 10)
+			canonical := canonicalRequest(signed.Method, signed.URL.EscapedPath(), signed.URL.RawQuery, timestamp, bodyHash)
+			signed.Header.Set(config.TimestampHeader, timestamp)
+			signed.Header.Set(config.SignatureHeader, SignCanonical(config.Secret, canonical))
+
+			return next.RoundTrip(signed)
+		})
+	}
+}
+
+// VerifyHMACRequest checks the signature of an inbound request and restores its body
+func VerifyHMACRequest(config HMACConfig, r *http.Request) error {
+	config = config.withDefaults()
+
+	signature := r.Header.Get(config.SignatureHeader)
+	timestamp := r.Header.Get(config.TimestampHeader)
+	if signature == "" || timestamp == "" {
+		return ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := config.TimeProvider.Now().Sub(time.Unix(seconds, 0))
+	if age > config.ReplayWindow || age < -config.ReplayWindow {
+		return ErrSignatureExpired
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(r.Body, config.MaxBodyBytes+1))
+		r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read body for verification: %w", err)
+		}
+		if int64(len(body)) > config.MaxBodyBytes {
+			return fmt.Errorf("%w: body exceeds %d bytes", ErrInvalidSignature, config.MaxBodyBytes)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	canonical := CanonicalRequest(r.Method, r.URL.EscapedPath(), r.URL.RawQuery, timestamp, body)
+	expected := SignCanonical(config.Secret, canonical)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyHMACSignature rejects inbound requests without a valid signature with 401
+func VerifyHMACSignature(config HMACConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := VerifyHMACRequest(config, r); err != nil {
+				WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hashRequestBody returns the hex SHA-256 of the request body. A body without GetBody
+// is consumed and returned buffered for the signed copy; req itself is never modified.
+func hashRequestBody(req *http.Request, maxBytes int64) (string, io.ReadCloser, error) {
+	hash := sha256.New()
+	if req.Body == nil || req.Body == http.NoBody {
+		return hex.EncodeToString(hash.Sum(nil)), nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", nil, err
+		}
+		defer body.Close()
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", nil, err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil, nil
+	}
+
+	if req.ContentLength <= 0 || req.ContentLength > maxBytes {
+		return "", nil, fmt.Errorf("%w: body of length %d has no GetBody", ErrUnsignableBody, req.ContentLength)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBytes))
+	req.Body.Close()
+	if err != nil {
+		return "", nil, err
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), io.NopCloser(bytes.NewReader(body)), nil
+}
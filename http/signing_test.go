@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigning_RoundTrip(t *testing.T) {
+	clock := newFakeClock()
+	config := HMACConfig{
+		Secret:       []byte("partner-secret"),
+		TimeProvider: clock,
+	}
+
+	var received map[string]interface{}
+	server := httptest.NewServer(VerifyHMACSignature(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode verified body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHMACSigning(config))
+
+	resp, err := client.Post("/webhooks/booking?b=2&a=1", map[string]interface{}{"pnr": "ABC123"}, nil)
+	if err != nil {
+		t.Fatalf("Client.Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Client.Post() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	if received["pnr"] != "ABC123" {
+		t.Errorf("Verified body = %v, want pnr ABC123", received)
+	}
+}
+
+func TestVerifyHMACRequest(t *testing.T) {
+	clock := newFakeClock()
+	config := HMACConfig{
+		Secret:       []byte("partner-secret"),
+		ReplayWindow: time.Minute,
+		TimeProvider: clock,
+	}
+
+	body := `{"status":"ticketed"}`
+	timestamp := strconv.FormatInt(clock.Now().Unix(), 10)
+	signature := SignCanonical(config.Secret, CanonicalRequest(http.MethodPost, "/hooks", "", timestamp, []byte(body)))
+
+	newRequest := func(sig, ts, payload string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(payload))
+		r.Header.Set(DefaultSignatureHeader, sig)
+		r.Header.Set(DefaultSignatureTimestampHeader, ts)
+		return r
+	}
+
+	if err := VerifyHMACRequest(config, newRequest(signature, timestamp, body)); err != nil {
+		t.Errorf("VerifyHMACRequest() error = %v", err)
+	}
+
+	if err := VerifyHMACRequest(config, newRequest(signature, timestamp, `{"status":"refunded"}`)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyHMACRequest() tampered body error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	if err := VerifyHMACRequest(config, newRequest("", timestamp, body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyHMACRequest() missing signature error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	clock.SetTime(clock.Now().Add(2 * time.Minute))
+	if err := VerifyHMACRequest(config, newRequest(signature, timestamp, body)); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("VerifyHMACRequest() replayed error = %v, want %v", err, ErrSignatureExpired)
+	}
+}
+
+func TestCanonicalRequest_SortsQuery(t *testing.T) {
+	first := CanonicalRequest("get", "/prices", "to=LED&from=MOW", "1700000000", nil)
+	second := CanonicalRequest("GET", "/prices", "from=MOW&to=LED", "1700000000", nil)
+
+	if first != second {
+		t.Errorf("CanonicalRequest() = %q, want %q", first, second)
+	}
+}
+
+func TestVerifyHMACSignature_Rejects(t *testing.T) {
+	handler := VerifyHMACSignature(HMACConfig{Secret: []byte("secret")})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler called for unsigned request")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader("{}")))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("VerifyHMACSignature() status = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHMACSigning_RefusesStreamedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unsigned request reached the server")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHMACSigning(HMACConfig{Secret: []byte("partner-secret")}))
+	files := []MultipartFile{{FieldName: "document", FileName: "ticket.pdf", Reader: strings.NewReader("%PDF-ticket")}}
+
+	_, err := client.PostMultipart(context.Background(), "/upload", nil, files, nil)
+	if !errors.Is(err, ErrUnsignableBody) {
+		t.Errorf("Client.PostMultipart() error = %v, want %v", err, ErrUnsignableBody)
+	}
+}
+
+func TestHMACSigning_HashesGetBodyWithoutConsuming(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"pnr":"ABC123"}`))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"pnr":"ABC123"}`)), nil
+	}
+
+	hash, buffered, err := hashRequestBody(req, 1)
+	if err != nil || buffered != nil {
+		t.Fatalf("hashRequestBody() buffered = %v, error = %v, want no buffer", buffered, err)
+	}
+	want := CanonicalRequest("POST", "/", "", "0", []byte(`{"pnr":"ABC123"}`))
+	if !strings.HasSuffix(want, hash) {
+		t.Errorf("hashRequestBody() = %v, want the hash used by CanonicalRequest", hash)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"pnr":"ABC123"}` {
+		t.Errorf("Request body after hashing = %q, want it unread", body)
+	}
+}
+
+func TestHMACSigning_LeavesInputRequestUnmodified(t *testing.T) {
+	var received string
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		received = string(body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	signer := HMACSigner(HMACConfig{Secret: []byte("partner-secret")})(transport)
+
+	original := io.NopCloser(strings.NewReader(`{"pnr":"ABC123"}`))
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", nil)
+	req.Body = original
+	req.ContentLength = int64(len(`{"pnr":"ABC123"}`))
+	req.GetBody = nil
+
+	if _, err := signer.RoundTrip(req); err != nil {
+		t.Fatalf("HMACSigner.RoundTrip() error = %v", err)
+	}
+	if req.Body != original {
+		t.Error("HMACSigner replaced the body of its input request")
+	}
+	if received != `{"pnr":"ABC123"}` {
+		t.Errorf("Signed request body = %q, want the original body", received)
+	}
+}
@@ -0,0 +1,385 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// NDJSONDecoder iterates over newline delimited JSON values
+type NDJSONDecoder struct {
+	reader *bufio.Reader
+	line   []byte
+	err    error
+}
+
+// NewNDJSONDecoder creates a decoder reading one JSON value per line from r
+func NewNDJSONDecoder(r io.Reader) *NDJSONDecoder {
+	return &NDJSONDecoder{reader: bufio.NewReader(r)}
+}
+
+// Next advances to the next non-empty line, returning false at the end of the stream or on error
+func (d *NDJSONDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			d.line = line
+			return true
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				d.err = fmt.Errorf("failed to read NDJSON line: %w", err)
+			}
+			return false
+		}
+	}
+}
+
+// Decode unmarshals the current line into v
+func (d *NDJSONDecoder) Decode(v interface{}) error {
+	if err := json.Unmarshal(d.line, v); err != nil {
+		return fmt.Errorf("failed to unmarshal NDJSON line: %w", err)
+	}
+	return nil
+}
+
+// Err returns the first read error encountered
+func (d *NDJSONDecoder) Err() error {
+	return d.err
+}
+
+// JSONArrayDecoder yields the elements of a top-level JSON array one by one
+type JSONArrayDecoder struct {
+	decoder *json.Decoder
+	started bool
+	done    bool
+	err     error
+}
+
+// NewJSONArrayDecoder creates a decoder for a JSON array read from r
+func NewJSONArrayDecoder(r io.Reader) *JSONArrayDecoder {
+	return &JSONArrayDecoder{decoder: json.NewDecoder(r)}
+}
+
+// Next reports whether another array element is available
+func (d *JSONArrayDecoder) Next() bool {
+	if d.err != nil || d.done {
+		return false
+	}
+
+	if !d.started {
+		d.started = true
+		token, err := d.decoder.Token()
+		if err != nil {
+			d.err = fmt.Errorf("failed to read JSON array start: %w", err)
+			return false
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			d.err = fmt.Errorf("expected JSON array, got %v", token)
+			return false
+		}
+	}
+
+	if d.decoder.More() {
+		return true
+	}
+
+	d.done = true
+	if _, err := d.decoder.Token(); err != nil {
+		d.err = fmt.Errorf("failed to read JSON array end: %w", err)
+	}
+	return false
+}
+
+// Decode unmarshals the next array element into v
+func (d *JSONArrayDecoder) Decode(v interface{}) error {
+	if err := d.decoder.Decode(v); err != nil {
+		d.err = fmt.Errorf("failed to decode JSON array element: %w", err)
+		return d.err
+	}
+	return nil
+}
+
+// Err returns the first error encountered
+func (d *JSONArrayDecoder) Err() error {
+	return d.err
+}
+
+// Event is a single Server-Sent Event
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// SSEConfig configures a Server-Sent Events subscription
+type SSEConfig struct {
+	// Headers are added to every connection attempt
+	Headers map[string]string
+	// LastEventID resumes the stream after this event
+	LastEventID string
+	// ReconnectDelay is the wait before reconnecting, overridden by the server retry field.
+	// Waits shorter than minReconnectDelay are raised to it.
+	ReconnectDelay time.Duration
+	// MaxReconnects limits consecutive reconnects without receiving an event, 0 means unlimited
+	MaxReconnects int
+}
+
+// minReconnectDelay keeps a server retry of 0 from turning reconnects into a busy loop
+const minReconnectDelay = 100 * time.Millisecond
+
+// DefaultSSEConfig returns default SSE configuration
+func DefaultSSEConfig() SSEConfig {
+	return SSEConfig{
+		ReconnectDelay: 3 * time.Second,
+	}
+}
+
+// SSEStream reads events from a Server-Sent Events endpoint, reconnecting when the connection drops
+type SSEStream struct {
+	client     *Client
+	ctx        context.Context
+	cancel     context.CancelFunc
+	endpoint   string
+	config     SSEConfig
+	body       io.ReadCloser
+	reader     *bufio.Reader
+	event      Event
+	reconnects int
+	err        error
+	closed     atomic.Bool
+}
+
+// Subscribe opens a Server-Sent Events stream; the connection is made on the first call to Next
+func (c *Client) Subscribe(ctx context.Context, endpoint string, config SSEConfig) *SSEStream {
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = DefaultSSEConfig().ReconnectDelay
+	}
+
+	// The client timeout would cut long-lived streams; the context and
+	// the transport's ResponseHeaderTimeout bound the connection instead
+	ctx, cancel := context.WithCancel(withStreaming(ctx))
+	return &SSEStream{
+		client:   c,
+		ctx:      ctx,
+		cancel:   cancel,
+		endpoint: endpoint,
+		config:   config,
+	}
+}
+
+// Next waits for the next event, returning false when the stream ends or fails
+func (s *SSEStream) Next() bool {
+	for s.err == nil {
+		if s.closed.Load() {
+			s.closeBody()
+			s.err = errStreamClosed
+			return false
+		}
+		if s.reader == nil {
+			if !s.connect() {
+				return false
+			}
+			if s.reader == nil {
+				// The attempt failed and the reconnect wait is over
+				continue
+			}
+		}
+
+		event, err := s.readEvent()
+		if err == nil {
+			s.event = event
+			s.reconnects = 0
+			return true
+		}
+
+		s.closeBody()
+		if s.ctx.Err() != nil {
+			s.err = s.contextErr()
+			return false
+		}
+		if !s.waitReconnect() {
+			return false
+		}
+	}
+	return false
+}
+
+// Event returns the current event
+func (s *SSEStream) Event() Event {
+	return s.event
+}
+
+// LastEventID returns the ID used to resume the stream
+func (s *SSEStream) LastEventID() string {
+	return s.config.LastEventID
+}
+
+// Err returns the error that ended the stream, nil if it ended normally
+func (s *SSEStream) Err() error {
+	if errors.Is(s.err, errStreamClosed) {
+		return nil
+	}
+	return s.err
+}
+
+// Close stops the stream and releases the connection. It may be called from another
+// goroutine to interrupt a blocked Next, which then cleans up and returns false.
+func (s *SSEStream) Close() error {
+	s.closed.Store(true)
+	s.cancel()
+	return nil
+}
+
+// contextErr returns the error for a stream whose context is done
+func (s *SSEStream) contextErr() error {
+	if s.closed.Load() {
+		return errStreamClosed
+	}
+	return s.ctx.Err()
+}
+
+// streamingKey is the context key marking requests that are not bound by the client timeout
+type streamingKey struct{}
+
+// withStreaming marks requests made with the context as long-lived streams
+func withStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+// isStreaming reports whether the context was marked by withStreaming
+func isStreaming(ctx context.Context) bool {
+	streaming, _ := ctx.Value(streamingKey{}).(bool)
+	return streaming
+}
+
+// errStreamClosed marks a stream closed by the server or the caller
+var errStreamClosed = errors.New("stream closed")
+
+// connect opens the event stream, returning false if the stream must stop.
+// A failed attempt that may be retried waits and returns true without a reader.
+func (s *SSEStream) connect() bool {
+	headers := map[string]string{
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+	for key, value := range s.config.Headers {
+		headers[key] = value
+	}
+	if s.config.LastEventID != "" {
+		headers["Last-Event-ID"] = s.config.LastEventID
+	}
+
+	resp, err := s.client.GetContext(s.ctx, s.endpoint, headers)
+	if err != nil {
+		if s.ctx.Err() != nil {
+			s.err = s.contextErr()
+			return false
+		}
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			// A non-2xx status ends the stream as it does without WithHTTPErrors
+			s.err = err
+			return false
+		}
+		return s.waitReconnect()
+	}
+
+	// The server signals the end of the stream with 204 No Content
+	if resp.StatusCode == http.StatusNoContent {
+		drainBody(resp)
+		s.err = errStreamClosed
+		return false
+	}
+	if !isSuccess(resp.StatusCode) {
+		s.err = NewHTTPError(resp)
+		return false
+	}
+
+	s.body = resp.Body
+	s.reader = bufio.NewReader(resp.Body)
+	return true
+}
+
+// waitReconnect sleeps before the next connection attempt, returning false if the stream must stop
+func (s *SSEStream) waitReconnect() bool {
+	s.reconnects++
+	if s.config.MaxReconnects > 0 && s.reconnects > s.config.MaxReconnects {
+		s.err = fmt.Errorf("failed to reconnect to event stream after %d attempts", s.config.MaxReconnects)
+		return false
+	}
+
+	if err := s.client.clock.Sleep(s.ctx, max(s.config.ReconnectDelay, minReconnectDelay)); err != nil {
+		s.err = s.contextErr()
+		return false
+	}
+	return true
+}
+
+// readEvent parses lines until a complete event with data is dispatched
+func (s *SSEStream) readEvent() (Event, error) {
+	var event Event
+	var data []string
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) == 0 {
+				event = Event{}
+				continue
+			}
+			event.ID = s.config.LastEventID
+			event.Data = strings.Join(data, "\n")
+			return event, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.config.LastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.config.ReconnectDelay = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// closeBody closes the current connection
+func (s *SSEStream) closeBody() {
+	if s.body != nil {
+		s.body.Close()
+		s.body = nil
+	}
+	s.reader = nil
+}
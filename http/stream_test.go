@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNDJSONDecoder(t *testing.T) {
+	input := "{\"id\":1,\"route\":\"MOW-LED\"}\n\n{\"id\":2,\"route\":\"LED-MOW\"}\n"
+	decoder := NewNDJSONDecoder(strings.NewReader(input))
+
+	var flights []testFlight
+	for decoder.Next() {
+		var flight testFlight
+		if err := decoder.Decode(&flight); err != nil {
+			t.Fatalf("NDJSONDecoder.Decode() error = %v", err)
+		}
+		flights = append(flights, flight)
+	}
+	if err := decoder.Err(); err != nil {
+		t.Fatalf("NDJSONDecoder.Err() = %v", err)
+	}
+
+	if len(flights) != 2 || flights[1].Route != "LED-MOW" {
+		t.Errorf("NDJSONDecoder decoded %+v, want MOW-LED and LED-MOW", flights)
+	}
+}
+
+func TestJSONArrayDecoder(t *testing.T) {
+	decoder := NewJSONArrayDecoder(strings.NewReader(`[{"id":1,"route":"MOW-LED"},{"id":2,"route":"LED-MOW"}]`))
+
+	var ids []int
+	for decoder.Next() {
+		var flight testFlight
+		if err := decoder.Decode(&flight); err != nil {
+			t.Fatalf("JSONArrayDecoder.Decode() error = %v", err)
+		}
+		ids = append(ids, flight.ID)
+	}
+	if err := decoder.Err(); err != nil {
+		t.Fatalf("JSONArrayDecoder.Err() = %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("JSONArrayDecoder decoded IDs %v, want [1 2]", ids)
+	}
+}
+
+func TestJSONArrayDecoder_NotArray(t *testing.T) {
+	decoder := NewJSONArrayDecoder(strings.NewReader(`{"origin":"MOW"}`))
+
+	if decoder.Next() {
+		t.Error("JSONArrayDecoder.Next() = true, want false for object")
+	}
+	if decoder.Err() == nil {
+		t.Error("JSONArrayDecoder.Err() expected error for object")
+	}
+}
+
+func TestSSEStream_Reconnect(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			if r.Header.Get("Accept") != "text/event-stream" {
+				t.Errorf("Expected Accept text/event-stream, got %v", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": keep-alive\n\nid: 1\nevent: price\ndata: 4200\n\nid: 2\ndata: line one\ndata: line two\n\n")
+		case 2:
+			if r.Header.Get("Last-Event-ID") != "2" {
+				t.Errorf("Expected Last-Event-ID 2, got %v", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "retry: 10\nid: 3\ndata: done\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithClock(newFakeClock()))
+	stream := client.Subscribe(context.Background(), "/search/stream", DefaultSSEConfig())
+	defer stream.Close()
+
+	var events []Event
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("SSEStream.Err() = %v", err)
+	}
+
+	want := []Event{
+		{ID: "1", Event: "price", Data: "4200"},
+		{ID: "2", Data: "line one\nline two"},
+		{ID: "3", Data: "done"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("SSEStream received %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("SSEStream event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+	if stream.LastEventID() != "3" {
+		t.Errorf("SSEStream.LastEventID() = %v, want 3", stream.LastEventID())
+	}
+}
+
+func TestSSEStream_MaxReconnects(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithClock(newFakeClock()))
+	config := DefaultSSEConfig()
+	config.MaxReconnects = 2
+	stream := client.Subscribe(context.Background(), "/events", config)
+	defer stream.Close()
+
+	if stream.Next() {
+		t.Fatal("SSEStream.Next() = true, want false for empty stream")
+	}
+	if stream.Err() == nil {
+		t.Error("SSEStream.Err() expected error after exhausting reconnects")
+	}
+	if connections != 3 {
+		t.Errorf("Server connections = %d, want 3", connections)
+	}
+}
+
+func TestSSEStream_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, http.StatusNotFound, "Stream not found")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	stream := client.Subscribe(context.Background(), "/missing", DefaultSSEConfig())
+	defer stream.Close()
+
+	if stream.Next() {
+		t.Fatal("SSEStream.Next() = true, want false")
+	}
+	if !IsNotFound(stream.Err()) {
+		t.Errorf("SSEStream.Err() = %v, want not found HTTPError", stream.Err())
+	}
+}
+
+func TestSSEStream_OutlivesClientTimeout(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 1 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 1\ndata: first\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		fmt.Fprint(w, "id: 2\ndata: second\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClientWithTimeout(server.URL, 50*time.Millisecond, WithClock(newFakeClock()))
+	stream := client.Subscribe(context.Background(), "/events", DefaultSSEConfig())
+	defer stream.Close()
+
+	var events []Event
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("SSEStream.Err() = %v", err)
+	}
+	if len(events) != 2 || connections != 2 {
+		t.Errorf("SSEStream received %d events over %d connections, want 2 events over 2", len(events), connections)
+	}
+}
+
+func TestSSEStream_ZeroRetryKeepsMinimumDelay(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connections, 1) > 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprint(w, "retry: 0\ndata: tick\n\n")
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+	client := NewClient(server.URL, WithClock(clock))
+	stream := client.Subscribe(context.Background(), "/events", DefaultSSEConfig())
+	defer stream.Close()
+
+	for stream.Next() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("SSEStream.Err() = %v", err)
+	}
+	if waited := clock.Now().Sub(start); waited < 3*minReconnectDelay {
+		t.Errorf("SSEStream waited %v over 3 reconnects, want at least %v", waited, 3*minReconnectDelay)
+	}
+}
+
+func TestSSEStream_CloseInterruptsNext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	stream := client.Subscribe(context.Background(), "/events", DefaultSSEConfig())
+
+	done := make(chan bool)
+	go func() {
+		for stream.Next() {
+		}
+		done <- true
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	stream.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SSEStream.Next() still blocked after Close")
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("SSEStream.Err() = %v, want nil after Close", err)
+	}
+}
+
+func TestSSEStream_DialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient(server.URL, WithClock(newFakeClock()))
+	config := DefaultSSEConfig()
+	config.MaxReconnects = 3
+	stream := client.Subscribe(context.Background(), "/events", config)
+	defer stream.Close()
+
+	if stream.Next() {
+		t.Fatal("SSEStream.Next() = true, want false for unreachable server")
+	}
+	if stream.Err() == nil {
+		t.Error("SSEStream.Err() expected error after exhausting reconnects")
+	}
+}
+
+func TestSSEStream_HTTPErrorWithHTTPErrors(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		WriteErrorResponse(w, http.StatusNotFound, "Stream not found")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHTTPErrors(), WithClock(newFakeClock()))
+	stream := client.Subscribe(context.Background(), "/missing", DefaultSSEConfig())
+	defer stream.Close()
+
+	if stream.Next() {
+		t.Fatal("SSEStream.Next() = true, want false")
+	}
+	if !IsNotFound(stream.Err()) || connections != 1 {
+		t.Errorf("SSEStream.Err() = %v after %d connections, want not found HTTPError after 1", stream.Err(), connections)
+	}
+}
@@ -0,0 +1,404 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
+	"github.com/KamnevVladimir/aviabot-shared-utils/providers"
+)
+
+const (
+	// TraceparentHeader carries the W3C trace context
+	TraceparentHeader = "traceparent"
+	// TracestateHeader carries vendor-specific trace state
+	TracestateHeader = "tracestate"
+)
+
+// ErrInvalidTraceparent is returned when a traceparent header cannot be parsed
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	TraceID    string
+	SpanID     string
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid reports whether the trace and span IDs are well formed
+func (sc SpanContext) IsValid() bool {
+	return isTraceHex(sc.TraceID, 32) && isTraceHex(sc.SpanID, 16)
+}
+
+// Traceparent formats the span context as a traceparent header value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses a traceparent header value
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	sc := SpanContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Sampled: flags[0]&0x01 == 0x01,
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	return sc, nil
+}
+
+// isTraceHex reports whether s is lowercase hex of the given length and not all zeros
+func isTraceHex(s string, length int) bool {
+	if len(s) != length || strings.Trim(s, "0") == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// spanContextKey is the context key for the active span context
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a context carrying the span context
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context stored in the context, if any
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// SpanKind describes the role of a span
+type SpanKind string
+
+const (
+	// SpanKindClient marks outbound requests
+	SpanKindClient SpanKind = "client"
+	// SpanKindServer marks inbound requests
+	SpanKindServer SpanKind = "server"
+)
+
+// Span records a timed operation within a trace
+type Span struct {
+	Name         string            `json:"name"`
+	Kind         SpanKind          `json:"kind"`
+	Context      SpanContext       `json:"context"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+
+	tracer *Tracer
+	mu     sync.Mutex
+}
+
+// SetAttribute records a key/value pair on the span
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Error = err.Error()
+}
+
+// End finishes the span and passes it to the tracer hooks
+func (s *Span) End() {
+	s.mu.Lock()
+	s.EndTime = s.tracer.config.TimeProvider.Now()
+	s.mu.Unlock()
+
+	for _, hook := range s.tracer.config.Hooks {
+		hook.OnEnd(s)
+	}
+}
+
+// Duration returns how long the span took
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// SpanHook observes span lifecycle events; exporters implement it
+type SpanHook interface {
+	OnStart(span *Span)
+	OnEnd(span *Span)
+}
+
+// TracerConfig configures a Tracer
+type TracerConfig struct {
+	// Hooks receive span start and end events
+	Hooks []SpanHook
+	// TimeProvider supplies span timestamps, defaults to the system time
+	TimeProvider interfaces.TimeProvider
+	// TraceIDGenerator creates 32 hex character trace IDs
+	TraceIDGenerator interfaces.IDGenerator
+	// SpanIDGenerator creates 16 hex character span IDs
+	SpanIDGenerator interfaces.IDGenerator
+}
+
+// Tracer creates spans and propagates W3C trace context
+type Tracer struct {
+	config TracerConfig
+}
+
+// NewTracer creates a new Tracer
+func NewTracer(config TracerConfig) *Tracer {
+	if config.TimeProvider == nil {
+		config.TimeProvider = systemClock
+	}
+	if config.TraceIDGenerator == nil {
+		config.TraceIDGenerator = providers.NewHexIDGenerator(32)
+	}
+	if config.SpanIDGenerator == nil {
+		config.SpanIDGenerator = providers.NewHexIDGenerator(16)
+	}
+
+	return &Tracer{config: config}
+}
+
+// Start begins a span as a child of the span context in ctx, or a new trace
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		StartTime:  t.config.TimeProvider.Now(),
+		Attributes: make(map[string]string),
+		tracer:     t,
+	}
+
+	if parent, ok := SpanContextFromContext(ctx); ok && parent.IsValid() {
+		span.Context = SpanContext{
+			TraceID:    parent.TraceID,
+			Sampled:    parent.Sampled,
+			TraceState: parent.TraceState,
+		}
+		span.ParentSpanID = parent.SpanID
+	} else {
+		span.Context = SpanContext{
+			TraceID: t.config.TraceIDGenerator.Generate(),
+			Sampled: true,
+		}
+	}
+	span.Context.SpanID = t.config.SpanIDGenerator.Generate()
+
+	for _, hook := range t.config.Hooks {
+		hook.OnStart(span)
+	}
+
+	return ContextWithSpanContext(ctx, span.Context), span
+}
+
+// WithTracer records a client span per attempt and injects trace context headers
+func WithTracer(tracer *Tracer) Option {
+	return func(c *Client) {
+		c.use(stageTelemetry, tracer.Middleware())
+	}
+}
+
+// Middleware returns client middleware that traces each outbound request
+func (t *Tracer) Middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := t.Start(req.Context(), "HTTP "+req.Method, SpanKindClient)
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.url", req.URL.String())
+
+			outbound := req.Clone(ctx)
+			outbound.Header.Set(TraceparentHeader, span.Context.Traceparent())
+			if span.Context.TraceState != "" {
+				outbound.Header.Set(TracestateHeader, span.Context.TraceState)
+			}
+
+			resp, err := next.RoundTrip(outbound)
+			if err != nil {
+				span.SetError(err)
+			} else {
+				span.SetAttribute("http.status_code", fmt.Sprint(resp.StatusCode))
+				if resp.StatusCode >= 500 {
+					span.SetError(fmt.Errorf("status %d", resp.StatusCode))
+				}
+			}
+			span.End()
+
+			return resp, err
+		})
+	}
+}
+
+// TracingMiddleware extracts trace context from inbound requests and records a server span
+func TracingMiddleware(tracer *Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if parent, err := ParseTraceparent(r.Header.Get(TraceparentHeader)); err == nil {
+				parent.TraceState = r.Header.Get(TracestateHeader)
+				ctx = ContextWithSpanContext(ctx, parent)
+			}
+
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method+" "+r.URL.Path, SpanKindServer)
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.target", r.URL.RequestURI())
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttribute("http.status_code", fmt.Sprint(recorder.status))
+			if recorder.status >= 500 {
+				span.SetError(fmt.Errorf("status %d", recorder.status))
+			}
+			span.End()
+		})
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush sends buffered data to the client when the underlying writer supports it
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets protocol upgrades take over the connection
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// InMemoryExporter keeps finished spans in memory for tests and debugging
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter creates a new InMemoryExporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// OnStart ignores started spans
+func (e *InMemoryExporter) OnStart(span *Span) {}
+
+// OnEnd stores the finished span
+func (e *InMemoryExporter) OnEnd(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the finished spans in end order
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	spans := make([]*Span, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset discards all stored spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
+
+// WriterExporter writes finished spans as JSON lines
+type WriterExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterExporter creates a WriterExporter writing to w
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{w: w}
+}
+
+// NewStdoutExporter creates a WriterExporter writing to standard output
+func NewStdoutExporter() *WriterExporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+// OnStart ignores started spans
+func (e *WriterExporter) OnStart(span *Span) {}
+
+// OnEnd writes the finished span as a JSON line
+func (e *WriterExporter) OnEnd(span *Span) {
+	span.mu.Lock()
+	line, err := json.Marshal(span)
+	span.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.w.Write(append(line, '\n'))
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+		sampled bool
+	}{
+		{"valid sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, true},
+		{"valid unsampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", true, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true, false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, false},
+		{"too short", "00-4bf92f3577b34da6-01", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceparent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if sc.Sampled != tt.sampled {
+				t.Errorf("ParseTraceparent() sampled = %v, want %v", sc.Sampled, tt.sampled)
+			}
+			if sc.Traceparent() != tt.value {
+				t.Errorf("SpanContext.Traceparent() = %v, want %v", sc.Traceparent(), tt.value)
+			}
+		})
+	}
+}
+
+func TestTracer_PropagatesAcrossServices(t *testing.T) {
+	serverSpans := NewInMemoryExporter()
+	serverTracer := NewTracer(TracerConfig{Hooks: []SpanHook{serverSpans}})
+
+	var handlerContext SpanContext
+	server := httptest.NewServer(TracingMiddleware(serverTracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerContext, _ = SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer server.Close()
+
+	clientSpans := NewInMemoryExporter()
+	clientTracer := NewTracer(TracerConfig{Hooks: []SpanHook{clientSpans}})
+	client := NewClient(server.URL, WithTracer(clientTracer))
+
+	ctx, root := clientTracer.Start(context.Background(), "search", SpanKindServer)
+	resp, err := client.GetContext(ctx, "/flights", nil)
+	if err != nil {
+		t.Fatalf("Client.GetContext() error = %v", err)
+	}
+	resp.Body.Close()
+	root.End()
+
+	spans := clientSpans.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Client spans = %d, want 2", len(spans))
+	}
+	clientSpan := spans[0]
+	if clientSpan.Kind != SpanKindClient || clientSpan.ParentSpanID != root.Context.SpanID {
+		t.Errorf("Client span = %+v, want child of %v", clientSpan, root.Context.SpanID)
+	}
+	if clientSpan.Attributes["http.status_code"] != "200" {
+		t.Errorf("Client span status = %v, want 200", clientSpan.Attributes["http.status_code"])
+	}
+
+	received := serverSpans.Spans()
+	if len(received) != 1 {
+		t.Fatalf("Server spans = %d, want 1", len(received))
+	}
+	serverSpan := received[0]
+	if serverSpan.Context.TraceID != root.Context.TraceID {
+		t.Errorf("Server span trace ID = %v, want %v", serverSpan.Context.TraceID, root.Context.TraceID)
+	}
+	if serverSpan.ParentSpanID != clientSpan.Context.SpanID {
+		t.Errorf("Server span parent = %v, want %v", serverSpan.ParentSpanID, clientSpan.Context.SpanID)
+	}
+	if handlerContext.SpanID != serverSpan.Context.SpanID {
+		t.Errorf("Handler span ID = %v, want %v", handlerContext.SpanID, serverSpan.Context.SpanID)
+	}
+}
+
+func TestTracer_NewTraceWithoutParent(t *testing.T) {
+	clock := newFakeClock()
+	tracer := NewTracer(TracerConfig{TimeProvider: clock})
+
+	_, span := tracer.Start(context.Background(), "job", SpanKindServer)
+	clock.SetTime(clock.Now().Add(250 * time.Millisecond))
+	span.End()
+
+	if !span.Context.IsValid() {
+		t.Errorf("Span context = %+v, want valid IDs", span.Context)
+	}
+	if span.ParentSpanID != "" {
+		t.Errorf("Span parent = %v, want empty", span.ParentSpanID)
+	}
+	if span.Duration() != 250*time.Millisecond {
+		t.Errorf("Span.Duration() = %v, want %v", span.Duration(), 250*time.Millisecond)
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(TracerConfig{Hooks: []SpanHook{NewWriterExporter(&buf)}})
+
+	_, span := tracer.Start(context.Background(), "export", SpanKindClient)
+	span.SetAttribute("route", "MOW-LED")
+	span.End()
+
+	var exported map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("Failed to unmarshal exported span: %v", err)
+	}
+	if exported["name"] != "export" {
+		t.Errorf("Exported span name = %v, want export", exported["name"])
+	}
+}
+
+func TestTracingMiddleware_ForwardsFlush(t *testing.T) {
+	handler := TracingMiddleware(NewTracer(TracerConfig{}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Fatal("Expected response writer to implement http.Flusher")
+		}
+		w.Write([]byte("data: 1\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("ResponseController.Flush() error = %v", err)
+		}
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if !recorder.Flushed {
+		t.Error("Expected flush to reach the underlying writer")
+	}
+}
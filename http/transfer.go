@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+var (
+	// ErrDownloadTooLarge is returned when a download exceeds its size limit
+	ErrDownloadTooLarge = errors.New("download exceeds size limit")
+	// ErrChecksumMismatch is returned when downloaded content does not match the expected checksum
+	ErrChecksumMismatch = errors.New("download checksum mismatch")
+)
+
+// MultipartFile is a file part of a multipart upload
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// PostMultipart streams fields and files as multipart/form-data without buffering them.
+// The body cannot be replayed, so the request is never retried.
+func (c *Client) PostMultipart(ctx context.Context, endpoint string, fields map[string]string, files []MultipartFile, headers map[string]string) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, files))
+	}()
+
+	req, err := c.newRequest(ctx, http.MethodPost, endpoint, pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.setHeaders(req, headers)
+
+	resp, err := c.send(req, endpoint)
+	if err != nil {
+		// Unblock the writer if the request failed before the body was consumed
+		pr.CloseWithError(err)
+	}
+	return resp, err
+}
+
+// writeMultipart writes every field and file part and the closing boundary
+func writeMultipart(writer *multipart.Writer, fields map[string]string, files []MultipartFile) error {
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return fmt.Errorf("failed to write field %q: %w", name, err)
+		}
+	}
+
+	for _, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.FieldName), escapeQuotes(file.FileName)))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("failed to create part %q: %w", file.FieldName, err)
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return fmt.Errorf("failed to write file %q: %w", file.FileName, err)
+		}
+	}
+
+	return writer.Close()
+}
+
+// escapeQuotes escapes quotes and backslashes in Content-Disposition parameters
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// DownloadOptions configures Client.Download
+type DownloadOptions struct {
+	// MaxBytes limits the download size, 0 means unlimited
+	MaxBytes int64
+	// Progress is called after each chunk with the bytes written and the total, -1 if unknown
+	Progress func(written, total int64)
+	// SHA256 is the expected hex encoded checksum of the content, empty skips verification
+	SHA256 string
+	// Headers are added to the request
+	Headers map[string]string
+}
+
+// Download streams the response body of a GET request to w.
+// Content is written as it arrives, so w holds partial data if a limit or checksum check fails.
+// Like Subscribe, downloads are bounded by ctx rather than the client timeout.
+func (c *Client) Download(ctx context.Context, endpoint string, w io.Writer, opts DownloadOptions) (int64, error) {
+	resp, err := c.GetContext(withStreaming(ctx), endpoint, opts.Headers)
+	if err != nil {
+		return 0, err
+	}
+	if !isSuccess(resp.StatusCode) {
+		return 0, NewHTTPError(resp)
+	}
+	defer resp.Body.Close()
+
+	if opts.MaxBytes > 0 && resp.ContentLength > opts.MaxBytes {
+		return 0, fmt.Errorf("%w: %d bytes exceeds %d", ErrDownloadTooLarge, resp.ContentLength, opts.MaxBytes)
+	}
+
+	var hasher hash.Hash
+	writers := []io.Writer{w}
+	if opts.SHA256 != "" {
+		hasher = sha256.New()
+		writers = append(writers, hasher)
+	}
+	if opts.Progress != nil {
+		writers = append(writers, &progressWriter{total: resp.ContentLength, report: opts.Progress})
+	}
+
+	var body io.Reader = resp.Body
+	if opts.MaxBytes > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxBytes)
+	}
+
+	written, err := io.Copy(io.MultiWriter(writers...), body)
+	if err != nil {
+		return written, fmt.Errorf("failed to download: %w", err)
+	}
+	if opts.MaxBytes > 0 && written == opts.MaxBytes {
+		// Probe for one more byte without passing it on to w
+		n, err := io.ReadFull(resp.Body, make([]byte, 1))
+		if n > 0 {
+			return written, fmt.Errorf("%w: more than %d bytes", ErrDownloadTooLarge, opts.MaxBytes)
+		}
+		if err != io.EOF {
+			return written, fmt.Errorf("failed to download: %w", err)
+		}
+	}
+
+	if hasher != nil {
+		actual := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(actual, opts.SHA256) {
+			return written, fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, actual, opts.SHA256)
+		}
+	}
+
+	return written, nil
+}
+
+// progressWriter reports cumulative bytes written
+type progressWriter struct {
+	written int64
+	total   int64
+	report  func(written, total int64)
+}
+
+// Write counts the bytes and reports progress
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	p.report(p.written, p.total)
+	return len(b), nil
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_PostMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse multipart form: %v", err)
+		}
+		if r.FormValue("chat_id") != "42" {
+			t.Errorf("Expected chat_id=42, got %v", r.FormValue("chat_id"))
+		}
+
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			t.Fatalf("Failed to read file part: %v", err)
+		}
+		defer file.Close()
+
+		content, _ := io.ReadAll(file)
+		if string(content) != "%PDF-ticket" {
+			t.Errorf("File content = %v, want %%PDF-ticket", string(content))
+		}
+		if header.Filename != "ticket.pdf" {
+			t.Errorf("File name = %v, want ticket.pdf", header.Filename)
+		}
+		if header.Header.Get("Content-Type") != "application/pdf" {
+			t.Errorf("File content type = %v, want application/pdf", header.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	files := []MultipartFile{{
+		FieldName:   "document",
+		FileName:    "ticket.pdf",
+		ContentType: "application/pdf",
+		Reader:      strings.NewReader("%PDF-ticket"),
+	}}
+
+	resp, err := client.PostMultipart(context.Background(), "/sendDocument", map[string]string{"chat_id": "42"}, files, nil)
+	if err != nil {
+		t.Fatalf("Client.PostMultipart() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Client.PostMultipart() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestClient_Download(t *testing.T) {
+	content := strings.Repeat("boarding-pass-", 1000)
+	checksum := sha256.Sum256([]byte(content))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	var buf bytes.Buffer
+	var lastProgress int64
+	written, err := client.Download(context.Background(), "/pass.png", &buf, DownloadOptions{
+		SHA256:   hex.EncodeToString(checksum[:]),
+		Progress: func(written, total int64) { lastProgress = written },
+	})
+	if err != nil {
+		t.Fatalf("Client.Download() error = %v", err)
+	}
+
+	if written != int64(len(content)) || buf.String() != content {
+		t.Errorf("Client.Download() wrote %d bytes, want %d", written, len(content))
+	}
+	if lastProgress != written {
+		t.Errorf("Progress reported %d bytes, want %d", lastProgress, written)
+	}
+}
+
+func TestClient_DownloadLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.Download(context.Background(), "/big", io.Discard, DownloadOptions{MaxBytes: 100})
+	if !errors.Is(err, ErrDownloadTooLarge) {
+		t.Errorf("Client.Download() error = %v, want %v", err, ErrDownloadTooLarge)
+	}
+
+	_, err = client.Download(context.Background(), "/big", io.Discard, DownloadOptions{SHA256: "deadbeef"})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Client.Download() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+}
+
+func TestClient_DownloadNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, http.StatusNotFound, "Ticket not found")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	if _, err := client.Download(context.Background(), "/missing", io.Discard, DownloadOptions{}); !IsNotFound(err) {
+		t.Errorf("Client.Download() error = %v, want not found HTTPError", err)
+	}
+}
+
+func TestClient_PostMultipartRejectedDoesNotLeak(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{
+		Limit: RateLimit{Rate: 0.001, Burst: 1},
+		Mode:  RateLimitFailFast,
+		Clock: newFakeClock(),
+	})
+	limiter.Allow("")
+	client := NewClient("http://127.0.0.1:1", WithRateLimiter(limiter))
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		files := []MultipartFile{{FieldName: "document", FileName: "ticket.pdf", Reader: strings.NewReader("%PDF-ticket")}}
+		if _, err := client.PostMultipart(context.Background(), "/upload", map[string]string{"chat_id": "42"}, files, nil); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("Client.PostMultipart() error = %v, want %v", err, ErrRateLimited)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if leaked := runtime.NumGoroutine() - before; leaked > 0 {
+		t.Errorf("PostMultipart left %d writer goroutines running", leaked)
+	}
+}
+
+func TestClient_DownloadLimitStopsAtMaxBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	var buf bytes.Buffer
+	var lastProgress int64
+	_, err := client.Download(context.Background(), "/big", &buf, DownloadOptions{
+		MaxBytes: 100,
+		Progress: func(written, total int64) { lastProgress = written },
+	})
+	if !errors.Is(err, ErrDownloadTooLarge) {
+		t.Fatalf("Client.Download() error = %v, want %v", err, ErrDownloadTooLarge)
+	}
+	if buf.Len() != 100 || lastProgress != 100 {
+		t.Errorf("Client.Download() wrote %d bytes and reported %d, want 100", buf.Len(), lastProgress)
+	}
+}
+
+func TestClient_DownloadOutlivesClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first-"))
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("second"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithTimeout(server.URL, 50*time.Millisecond)
+
+	var buf bytes.Buffer
+	if _, err := client.Download(context.Background(), "/slow", &buf, DownloadOptions{}); err != nil {
+		t.Fatalf("Client.Download() error = %v", err)
+	}
+	if buf.String() != "first-second" {
+		t.Errorf("Client.Download() content = %q, want %q", buf.String(), "first-second")
+	}
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/config"
+)
+
+// TransportConfig configures connection handling, proxying and TLS of the base transport
+type TransportConfig struct {
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	// MaxConnsPerHost limits all connections to a host, 0 means unlimited
+	MaxConnsPerHost int
+
+	// ProxyURL routes requests through a proxy, empty uses the environment
+	ProxyURL string
+	// DisableProxy ignores proxy environment variables
+	DisableProxy bool
+
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// RootCAs replaces the system roots when set
+	RootCAs *x509.CertPool
+	// CertFile and KeyFile hold a PEM client certificate for mTLS
+	CertFile string
+	KeyFile  string
+	// Certificates are client certificates presented for mTLS
+	Certificates []tls.Certificate
+	ServerName   string
+	// InsecureSkipVerify disables certificate verification; it is only settable in code
+	// so a configuration change cannot silently turn verification off
+	InsecureSkipVerify bool
+}
+
+// DefaultTransportConfig returns transport settings tuned for services calling many upstreams
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		DialTimeout:           5 * time.Second,
+		KeepAlive:             30 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          500,
+		MaxIdleConnsPerHost:   100,
+	}
+}
+
+// TransportOption configures a TransportConfig
+type TransportOption func(*TransportConfig)
+
+// WithDialTimeout sets the TCP connect timeout
+func WithDialTimeout(d time.Duration) TransportOption {
+	return func(c *TransportConfig) {
+		c.DialTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout sets the TLS handshake timeout
+func WithTLSHandshakeTimeout(d time.Duration) TransportOption {
+	return func(c *TransportConfig) {
+		c.TLSHandshakeTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout sets how long to wait for response headers after sending the request
+func WithResponseHeaderTimeout(d time.Duration) TransportOption {
+	return func(c *TransportConfig) {
+		c.ResponseHeaderTimeout = d
+	}
+}
+
+// WithIdleConnPool sizes the idle connection pool
+func WithIdleConnPool(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) TransportOption {
+	return func(c *TransportConfig) {
+		c.MaxIdleConns = maxIdle
+		c.MaxIdleConnsPerHost = maxIdlePerHost
+		c.IdleConnTimeout = idleTimeout
+	}
+}
+
+// WithMaxConnsPerHost limits all connections to a single host
+func WithMaxConnsPerHost(n int) TransportOption {
+	return func(c *TransportConfig) {
+		c.MaxConnsPerHost = n
+	}
+}
+
+// WithProxy routes requests through the proxy URL
+func WithProxy(proxyURL string) TransportOption {
+	return func(c *TransportConfig) {
+		c.ProxyURL = proxyURL
+	}
+}
+
+// WithoutProxy connects directly, ignoring proxy environment variables
+func WithoutProxy() TransportOption {
+	return func(c *TransportConfig) {
+		c.DisableProxy = true
+	}
+}
+
+// WithCABundle trusts the certificates in the PEM file in addition to the system roots
+func WithCABundle(file string) TransportOption {
+	return func(c *TransportConfig) {
+		c.CAFile = file
+	}
+}
+
+// WithRootCAs replaces the system roots with the pool
+func WithRootCAs(pool *x509.CertPool) TransportOption {
+	return func(c *TransportConfig) {
+		c.RootCAs = pool
+	}
+}
+
+// WithClientCertificate presents the PEM certificate and key for mTLS
+func WithClientCertificate(certFile, keyFile string) TransportOption {
+	return func(c *TransportConfig) {
+		c.CertFile = certFile
+		c.KeyFile = keyFile
+	}
+}
+
+// WithClientCertificates presents already loaded certificates for mTLS
+func WithClientCertificates(certs ...tls.Certificate) TransportOption {
+	return func(c *TransportConfig) {
+		c.Certificates = append(c.Certificates, certs...)
+	}
+}
+
+// WithTLSServerName overrides the server name used to verify certificates
+func WithTLSServerName(name string) TransportOption {
+	return func(c *TransportConfig) {
+		c.ServerName = name
+	}
+}
+
+// TransportFromConfig reads transport settings from configuration keys starting with prefix,
+// e.g. HTTP_DIAL_TIMEOUT, HTTP_PROXY_URL, HTTP_TLS_CA_FILE, HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE
+// for the prefix "HTTP". Missing keys keep their current values.
+func TransportFromConfig(cfg *config.Config, prefix string) TransportOption {
+	key := func(name string) string {
+		return prefix + "_" + name
+	}
+
+	return func(c *TransportConfig) {
+		c.DialTimeout = cfg.GetDurationWithDefault(key("DIAL_TIMEOUT"), c.DialTimeout)
+		c.KeepAlive = cfg.GetDurationWithDefault(key("KEEP_ALIVE"), c.KeepAlive)
+		c.TLSHandshakeTimeout = cfg.GetDurationWithDefault(key("TLS_HANDSHAKE_TIMEOUT"), c.TLSHandshakeTimeout)
+		c.ResponseHeaderTimeout = cfg.GetDurationWithDefault(key("RESPONSE_HEADER_TIMEOUT"), c.ResponseHeaderTimeout)
+		c.IdleConnTimeout = cfg.GetDurationWithDefault(key("IDLE_CONN_TIMEOUT"), c.IdleConnTimeout)
+		c.MaxIdleConns = cfg.GetIntWithDefault(key("MAX_IDLE_CONNS"), c.MaxIdleConns)
+		c.MaxIdleConnsPerHost = cfg.GetIntWithDefault(key("MAX_IDLE_CONNS_PER_HOST"), c.MaxIdleConnsPerHost)
+		c.MaxConnsPerHost = cfg.GetIntWithDefault(key("MAX_CONNS_PER_HOST"), c.MaxConnsPerHost)
+		c.ProxyURL = cfg.GetWithDefault(key("PROXY_URL"), c.ProxyURL)
+		c.DisableProxy = cfg.GetBoolWithDefault(key("DISABLE_PROXY"), c.DisableProxy)
+		c.CAFile = cfg.GetWithDefault(key("TLS_CA_FILE"), c.CAFile)
+		c.CertFile = cfg.GetWithDefault(key("TLS_CERT_FILE"), c.CertFile)
+		c.KeyFile = cfg.GetWithDefault(key("TLS_KEY_FILE"), c.KeyFile)
+		c.ServerName = cfg.GetWithDefault(key("TLS_SERVER_NAME"), c.ServerName)
+	}
+}
+
+// NewTransport builds an *http.Transport from DefaultTransportConfig and the options
+func NewTransport(opts ...TransportOption) (*http.Transport, error) {
+	cfg := DefaultTransportConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.DisableProxy {
+		proxy = nil
+	} else if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}, nil
+}
+
+// NewClientFromConfig creates a client whose timeout and transport are read from configuration keys
+// starting with prefix; the total timeout uses the <prefix>_TIMEOUT key and defaults to 30 seconds
+func NewClientFromConfig(baseURL string, cfg *config.Config, prefix string, opts ...Option) (*Client, error) {
+	transport, err := NewTransport(TransportFromConfig(cfg, prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.GetDurationWithDefault(prefix+"_TIMEOUT", 30*time.Second)
+	opts = append([]Option{WithTransport(transport)}, opts...)
+
+	return NewClientWithTimeout(baseURL, timeout, opts...), nil
+}
+
+// tlsConfig loads the CA bundle and client certificates
+func (c TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            c.RootCAs,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		Certificates:       append([]tls.Certificate(nil), c.Certificates...),
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool := tlsConfig.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	return tlsConfig, nil
+}
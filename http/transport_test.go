@@ -0,0 +1,221 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KamnevVladimir/aviabot-shared-utils/config"
+)
+
+// writeClientCertificate creates a self-signed client certificate and returns its PEM files
+func writeClientCertificate(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "aviabot"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	return certFile, keyFile, cert
+}
+
+// writeServerCA writes the TLS test server certificate as a CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	return file
+}
+
+func TestNewTransport_MutualTLS(t *testing.T) {
+	certFile, keyFile, clientCert := writeClientCertificate(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "aviabot" {
+			t.Error("Expected client certificate for aviabot")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	transport, err := NewTransport(
+		WithCABundle(writeServerCA(t, server)),
+		WithClientCertificate(certFile, keyFile),
+		WithoutProxy(),
+	)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	client := NewClient(server.URL, WithTransport(transport))
+
+	resp, err := client.Get("/internal/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Client.Get() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestNewTransport_UntrustedServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(WithRootCAs(x509.NewCertPool()))
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+	client := NewClient(server.URL, WithTransport(transport))
+
+	if _, err := client.Get("/", nil); err == nil {
+		t.Error("Client.Get() expected certificate verification error")
+	}
+}
+
+func TestNewTransport_Options(t *testing.T) {
+	transport, err := NewTransport(
+		WithDialTimeout(time.Second),
+		WithTLSHandshakeTimeout(2*time.Second),
+		WithResponseHeaderTimeout(3*time.Second),
+		WithIdleConnPool(10, 5, time.Minute),
+		WithMaxConnsPerHost(20),
+		WithProxy("http://proxy.internal:3128"),
+		WithTLSServerName("pricing.internal"),
+	)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("NewTransport() timeouts = %v/%v, want 2s/3s", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+	if transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 5 || transport.MaxConnsPerHost != 20 {
+		t.Errorf("NewTransport() pool = %d/%d/%d, want 10/5/20", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+	if transport.TLSClientConfig.ServerName != "pricing.internal" {
+		t.Errorf("NewTransport() server name = %v, want pricing.internal", transport.TLSClientConfig.ServerName)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://pricing.internal/prices", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.internal:3128" {
+		t.Errorf("NewTransport() proxy = %v, %v, want proxy.internal:3128", proxyURL, err)
+	}
+}
+
+func TestNewTransport_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  TransportOption
+	}{
+		{"missing CA bundle", WithCABundle(filepath.Join(t.TempDir(), "missing.pem"))},
+		{"missing client certificate", WithClientCertificate("missing.pem", "missing-key.pem")},
+		{"invalid proxy", WithProxy("://bad")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTransport(tt.opt); err == nil {
+				t.Errorf("NewTransport() expected error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.Set("PRICING_TIMEOUT", "5s")
+	cfg.Set("PRICING_DIAL_TIMEOUT", "1s")
+	cfg.Set("PRICING_MAX_IDLE_CONNS_PER_HOST", "42")
+	cfg.Set("PRICING_DISABLE_PROXY", "true")
+	cfg.Set("PRICING_TLS_CA_FILE", writeServerCA(t, server))
+
+	client, err := NewClientFromConfig(server.URL, cfg, "PRICING")
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("NewClientFromConfig() timeout = %v, want 5s", client.httpClient.Timeout)
+	}
+	if transport := client.transport.(*http.Transport); transport.MaxIdleConnsPerHost != 42 || transport.Proxy != nil {
+		t.Errorf("NewClientFromConfig() idle per host = %d, proxy set = %v, want 42 and no proxy",
+			transport.MaxIdleConnsPerHost, transport.Proxy != nil)
+	}
+
+	resp, err := client.Get("/prices", nil)
+	if err != nil {
+		t.Fatalf("Client.Get() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewClientFromConfig_IgnoresInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.Set("PRICING_TLS_INSECURE_SKIP_VERIFY", "true")
+
+	client, err := NewClientFromConfig(server.URL, cfg, "PRICING")
+	if err != nil {
+		t.Fatalf("NewClientFromConfig() error = %v", err)
+	}
+	if client.transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("NewClientFromConfig() disabled certificate verification from configuration")
+	}
+	if _, err := client.Get("/prices", nil); err == nil {
+		t.Error("Client.Get() expected certificate verification error")
+	}
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"github.com/KamnevVladimir/aviabot-shared-core/domain/interfaces"
 	"time"
 )
@@ -18,6 +19,19 @@ func (p *SystemTimeProvider) Now() time.Time {
 	return time.Now()
 }
 
+// Sleep waits for the duration or until the context is done
+func (p *SystemTimeProvider) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // FixedTimeProvider provides a fixed time for testing
 type FixedTimeProvider struct {
 	fixedTime time.Time
@@ -35,6 +49,15 @@ func (p *FixedTimeProvider) Now() time.Time {
 	return p.fixedTime
 }
 
+// Sleep advances the fixed time by the duration without blocking
+func (p *FixedTimeProvider) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.fixedTime = p.fixedTime.Add(d)
+	return nil
+}
+
 // SetTime updates the fixed time
 func (p *FixedTimeProvider) SetTime(newTime time.Time) {
 	p.fixedTime = newTime
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -61,3 +62,27 @@ func TestFixedTimeProvider_Interface(t *testing.T) {
 		t.Fatal("NewFixedTimeProvider() returned nil")
 	}
 }
+
+func TestSystemTimeProvider_Sleep(t *testing.T) {
+	provider := NewSystemTimeProvider().(*SystemTimeProvider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := provider.Sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("SystemTimeProvider.Sleep() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFixedTimeProvider_Sleep(t *testing.T) {
+	initialTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := NewFixedTimeProvider(initialTime).(*FixedTimeProvider)
+
+	if err := provider.Sleep(context.Background(), time.Minute); err != nil {
+		t.Fatalf("FixedTimeProvider.Sleep() error = %v", err)
+	}
+
+	if want := initialTime.Add(time.Minute); !provider.Now().Equal(want) {
+		t.Errorf("After Sleep = %v, want %v", provider.Now(), want)
+	}
+}